@@ -0,0 +1,118 @@
+package bytesize
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bitUnits holds the bit unit suffixes used by FormatBits and ParseBits.
+// Unlike byte units they end in a lower-case "b" and are not localized.
+var bitUnits = map[ByteSize]string{
+	B:  "b",
+	KB: "Kb",
+	MB: "Mb",
+	GB: "Gb",
+	TB: "Tb",
+	PB: "Pb",
+	EB: "Eb",
+}
+
+// FormatBits returns b as a number of bits, for network and bandwidth
+// displays: eight times the byte count, in the largest bit unit in which it
+// is at least 1, e.g. "8Mb" for (1 * MB).FormatBits("%.0f"). Prefixes follow
+// CurrentSystem, like byte units do. format follows the rules of the Format
+// global.
+func (b ByteSize) FormatBits(format string) string {
+	bits := float64(b) * 8
+	system := getSystem()
+	unit := B
+	for _, u := range allUnits {
+		if bits >= float64(system.unitBytes(u)) {
+			unit = u
+		}
+	}
+	value := bits / float64(system.unitBytes(unit))
+	return fmt.Sprintf(sanitizeFormat(format), value) + bitUnits[unit]
+}
+
+// ParseBits parses a number of bits such as "8 Mb", "100 Kbit" or
+// "1.5 Gbps" and returns it as bytes, rounded to the nearest byte, so
+// "8 Mb" is 1 MB. The unit must end in "b", "bit", "bits" or "bps"; the
+// prefix is case-insensitive and a trailing "/s" is ignored. Prefixes
+// follow CurrentSystem.
+func ParseBits(s string) (ByteSize, error) {
+	locale := getLocale()
+	units, ok := localizedUnits[locale]
+	if !ok {
+		locale, units = LocaleEN, localizedUnits[LocaleEN]
+	}
+
+	s = strings.TrimSuffix(strings.TrimSpace(s), "/s")
+	if s == "" {
+		return 0, parseError(locale, errEmptyInput, "")
+	}
+	numberPart, suffix := splitUnit(s)
+	unit, ok := parseBitUnit(suffix)
+	if !ok {
+		if suffix == "" {
+			return 0, parseError(locale, errMissingSuffix, "")
+		}
+		return 0, parseError(locale, errUnknownSuffix, suffix)
+	}
+	if strings.HasPrefix(numberPart, "-") {
+		return 0, parseError(locale, errNegativeSize, strconv.Quote(numberPart))
+	}
+	numberPart = strings.TrimPrefix(numberPart, "+")
+
+	groupSep, decimalSep := numberSeparators(numberPart, units)
+	number, rest := splitNumber(numberPart, groupSep, decimalSep)
+	if rest != "" || number == "" {
+		return 0, parseError(locale, errInvalidNumber, strconv.Quote(numberPart))
+	}
+
+	if unit != B {
+		bytes, err := scaleNumber(number, getSystem().unitBytes(unit)/8)
+		if err != nil && !errors.Is(err, ErrOverflow) {
+			return 0, parseError(locale, errInvalidNumber, strconv.Quote(number))
+		}
+		return bytes, err
+	}
+
+	// Plain bits are rounded to the nearest whole byte.
+	bits, err := scaleNumber(number, 1)
+	if err != nil && !errors.Is(err, ErrOverflow) {
+		return 0, parseError(locale, errInvalidNumber, strconv.Quote(number))
+	}
+	return bits/8 + (bits%8+4)/8, err
+}
+
+// parseBitUnit returns the unit of a bit suffix such as "Mb", "kbit" or
+// "Gbps". The final "b" must be lower case to tell bits from bytes.
+func parseBitUnit(suffix string) (ByteSize, bool) {
+	for _, ending := range []string{"bps", "bits", "bit", "b"} {
+		prefix, ok := strings.CutSuffix(suffix, ending)
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(prefix) {
+		case "":
+			return B, true
+		case "K":
+			return KB, true
+		case "M":
+			return MB, true
+		case "G":
+			return GB, true
+		case "T":
+			return TB, true
+		case "P":
+			return PB, true
+		case "E":
+			return EB, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+package bytesize
+
+import "testing"
+
+func TestFormatBits(t *testing.T) {
+	originalSystem := CurrentSystem
+	defer func() { CurrentSystem = originalSystem }()
+
+	tests := []struct {
+		size     ByteSize
+		format   string
+		system   UnitSystem
+		expected string
+	}{
+		{MB, "%.0f", SystemBinary, "8Mb"},
+		{MB, "%.2f ", SystemBinary, "8.00 Mb"},
+		{0, "%.0f", SystemBinary, "0b"},
+		{1, "%.0f", SystemBinary, "8b"},
+		{128, "%.0f", SystemBinary, "1Kb"},
+		{100, "%.2f ", SystemBinary, "800.00 b"},
+		{GB / 2, "%.0f", SystemBinary, "4Gb"},
+		{125000, "%.0f", SystemDecimal, "1Mb"},
+		{12500000, "%.0f ", SystemDecimal, "100 Mb"},
+		{2 * EB, "%.0f", SystemBinary, "16Eb"},
+		{MB, "bogus", SystemBinary, "8.00 Mb"},
+	}
+
+	for _, tt := range tests {
+		SetUnitSystem(tt.system)
+		if result := tt.size.FormatBits(tt.format); result != tt.expected {
+			t.Errorf("FormatBits(%d, %q) in %s = %q, expected %q", tt.size, tt.format, tt.system, result, tt.expected)
+		}
+	}
+}
+
+func TestParseBits(t *testing.T) {
+	originalSystem := CurrentSystem
+	defer func() { CurrentSystem = originalSystem }()
+
+	tests := []struct {
+		input    string
+		system   UnitSystem
+		expected ByteSize
+		fail     bool
+	}{
+		{"8 Mb", SystemBinary, MB, false},
+		{"8Mb", SystemBinary, MB, false},
+		{"8 mb", SystemBinary, MB, false},
+		{"1 Kbit", SystemBinary, 128, false},
+		{"16 kbits", SystemBinary, 2 * KB, false},
+		{"1.5 Gbps", SystemBinary, 3 * GB / 16, false},
+		{"80 Mb/s", SystemBinary, 10 * MB, false},
+		{"100 Mbps", SystemDecimal, 12500000, false},
+		{"12 b", SystemBinary, 2, false},
+		{"11 b", SystemBinary, 1, false},
+		{"+8 Mb", SystemBinary, MB, false},
+		{"8 MB", SystemBinary, 0, true},
+		{"8 Xb", SystemBinary, 0, true},
+		{"8", SystemBinary, 0, true},
+		{"", SystemBinary, 0, true},
+		{"Mb", SystemBinary, 0, true},
+		{"-8 Mb", SystemBinary, 0, true},
+		{"200 Eb", SystemBinary, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			SetUnitSystem(tt.system)
+			result, err := ParseBits(tt.input)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("ParseBits(%q): expected error, received %d", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBits(%q) error = %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseBits(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	// FormatBits output parses back.
+	SetUnitSystem(SystemBinary)
+	for _, size := range []ByteSize{8, KB, 3 * MB / 2, 7 * GB} {
+		if result, err := ParseBits(size.FormatBits("%.2f ")); err != nil || result != size {
+			t.Errorf("ParseBits(FormatBits(%d)) = %d, %v", size, result, err)
+		}
+	}
+}
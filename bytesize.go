@@ -3,7 +3,7 @@
 //
 // This package is a fork of the original bytesize library with added
 // internationalization features, including:
-//   - Multi-language support (English, Russian)
+//   - Multi-language support (English, Russian, Hindi, German, French, Polish)
 //   - Proper plural forms for different languages
 //   - Backward compatibility with the original API
 //   - Extended parsing capabilities
@@ -16,11 +16,18 @@
 package bytesize
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
+	"unicode/utf8"
 )
 
 // This code was originally based on http://golang.org/doc/progs/eff_bytesize.go
@@ -34,6 +41,36 @@ import (
 // ByteSize represents a number of bytes
 type ByteSize uint64
 
+// Less reports whether a is smaller than b. It can be passed to closures
+// given to sort.Slice.
+func Less(a, b ByteSize) bool { return a < b }
+
+// SortKey returns the raw number of bytes in b, a key that orders sizes the
+// same way Less does and can be shared with other sortable quantities.
+func (b ByteSize) SortKey() uint64 { return uint64(b) }
+
+// Less reports whether b is smaller than other, like b < other.
+func (b ByteSize) Less(other ByteSize) bool { return b < other }
+
+// Equal reports whether b and other are the same size, like b == other.
+func (b ByteSize) Equal(other ByteSize) bool { return b == other }
+
+// GreaterThan reports whether b is larger than other, like b > other.
+func (b ByteSize) GreaterThan(other ByteSize) bool { return b > other }
+
+// Compare returns -1, 0 or +1 depending on whether b is smaller than, equal
+// to or larger than other. It can be passed to slices.SortFunc as
+// ByteSize.Compare.
+func (b ByteSize) Compare(other ByteSize) int { return cmp.Compare(b, other) }
+
+// BySize implements sort.Interface for a slice of ByteSize in ascending
+// order.
+type BySize []ByteSize
+
+func (s BySize) Len() int           { return len(s) }
+func (s BySize) Less(i, j int) bool { return Less(s[i], s[j]) }
+func (s BySize) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
 // Byte size suffixes
 const (
 	B  ByteSize = 1
@@ -45,12 +82,28 @@ const (
 	EB
 )
 
+// allUnits lists the byte size suffixes in ascending order.
+var allUnits = []ByteSize{B, KB, MB, GB, TB, PB, EB}
+
+// unitLadder lists the units autoUnit may choose from, in ascending order.
+var unitLadder = allUnits
+
+// ErrOverflow is returned when a result does not fit in a ByteSize.
+var ErrOverflow = errors.New("byte size overflow")
+
+// ErrUnderflow is returned when a result would be negative.
+var ErrUnderflow = errors.New("byte size underflow")
+
 // Locale represents a supported locale
 type Locale string
 
 const (
 	LocaleEN Locale = "en"
 	LocaleRU Locale = "ru"
+	LocaleHI Locale = "hi"
+	LocaleDE Locale = "de"
+	LocaleFR Locale = "fr"
+	LocalePL Locale = "pl"
 )
 
 // unitDefinitions is a struct for unit definitions for different locales
@@ -59,8 +112,21 @@ type unitDefinitions struct {
 	longUnits map[ByteSize]string
 	// shortUnits used for returning string representation.
 	shortUnits map[ByteSize]string
+	// iecUnits used for returning binary (IEC) string representation.
+	iecUnits map[ByteSize]string
+	// siUnits used for returning decimal (SI) string representation.
+	siUnits map[ByteSize]string
 	// parseMap used to convert user input to ByteSize
 	parseMap map[string]ByteSize
+	// groupSeparator used between groups of thousands.
+	groupSeparator string
+	// decimalSeparator used between the integer and fractional digits.
+	decimalSeparator string
+	// conjunction used before the last item of a list, e.g. "and".
+	conjunction string
+	// pluralFunc returns the long name of unit inflected for value. Locales
+	// without one use longUnits unchanged.
+	pluralFunc func(value float64, unit ByteSize, forms unitDefinitions) string
 }
 
 // Localized unit definitions
@@ -84,15 +150,38 @@ var localizedUnits = map[Locale]unitDefinitions{
 			PB: "PB",
 			EB: "EB",
 		},
+		iecUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "KiB",
+			MB: "MiB",
+			GB: "GiB",
+			TB: "TiB",
+			PB: "PiB",
+			EB: "EiB",
+		},
+		siUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "kB",
+			MB: "MB",
+			GB: "GB",
+			TB: "TB",
+			PB: "PB",
+			EB: "EB",
+		},
 		parseMap: map[string]ByteSize{
 			"B": B, "BYTE": B, "BYTES": B,
-			"KB": KB, "KILOBYTE": KB, "KILOBYTES": KB,
-			"MB": MB, "MEGABYTE": MB, "MEGABYTES": MB,
-			"GB": GB, "GIGABYTE": GB, "GIGABYTES": GB,
-			"TB": TB, "TERABYTE": TB, "TERABYTES": TB,
-			"PB": PB, "PETABYTE": PB, "PETABYTES": PB,
-			"EB": EB, "EXABYTE": EB, "EXABYTES": EB,
+			"KB": KB, "KILOBYTE": KB, "KILOBYTES": KB, "KBYTE": KB, "KBYTES": KB,
+			"MB": MB, "MEGABYTE": MB, "MEGABYTES": MB, "MBYTE": MB, "MBYTES": MB,
+			"GB": GB, "GIGABYTE": GB, "GIGABYTES": GB, "GBYTE": GB, "GBYTES": GB,
+			"TB": TB, "TERABYTE": TB, "TERABYTES": TB, "TBYTE": TB, "TBYTES": TB,
+			"PB": PB, "PETABYTE": PB, "PETABYTES": PB, "PBYTE": PB, "PBYTES": PB,
+			"EB": EB, "EXABYTE": EB, "EXABYTES": EB, "EBYTE": EB, "EBYTES": EB,
+			"KIB": KB, "MIB": MB, "GIB": GB, "TIB": TB, "PIB": PB, "EIB": EB,
 		},
+		groupSeparator:   ",",
+		decimalSeparator: ".",
+		conjunction:      "and",
+		pluralFunc:       singularUnlessOne,
 	},
 	LocaleRU: {
 		longUnits: map[ByteSize]string{
@@ -113,27 +202,311 @@ var localizedUnits = map[Locale]unitDefinitions{
 			PB: "ПБ",
 			EB: "ЭБ",
 		},
+		iecUnits: map[ByteSize]string{
+			B:  "Б",
+			KB: "КиБ",
+			MB: "МиБ",
+			GB: "ГиБ",
+			TB: "ТиБ",
+			PB: "ПиБ",
+			EB: "ЭиБ",
+		},
+		siUnits: map[ByteSize]string{
+			B:  "Б",
+			KB: "кБ",
+			MB: "МБ",
+			GB: "ГБ",
+			TB: "ТБ",
+			PB: "ПБ",
+			EB: "ЭБ",
+		},
+		parseMap: map[string]ByteSize{
+			"Б": B, "БАЙТ": B, "БАЙТА": B, "БАЙТЫ": B, "БАЙТОВ": B,
+			"КБ": KB, "КИЛОБАЙТ": KB, "КИЛОБАЙТА": KB, "КИЛОБАЙТЫ": KB, "КИЛОБАЙТОВ": KB,
+			"МБ": MB, "МЕГАБАЙТ": MB, "МЕГАБАЙТА": MB, "МЕГАБАЙТЫ": MB, "МЕГАБАЙТОВ": MB,
+			"ГБ": GB, "ГИГАБАЙТ": GB, "ГИГАБАЙТА": GB, "ГИГАБАЙТЫ": GB, "ГИГАБАЙТОВ": GB,
+			"ТБ": TB, "ТЕРАБАЙТ": TB, "ТЕРАБАЙТА": TB, "ТЕРАБАЙТЫ": TB, "ТЕРАБАЙТОВ": TB,
+			"ПБ": PB, "ПЕТАБАЙТ": PB, "ПЕТАБАЙТА": PB, "ПЕТАБАЙТЫ": PB, "ПЕТАБАЙТОВ": PB,
+			"ЭБ": EB, "ЭКСАБАЙТ": EB, "ЭКСАБАЙТА": EB, "ЭКСАБАЙТЫ": EB, "ЭКСАБАЙТОВ": EB,
+			"КИБ": KB, "МИБ": MB, "ГИБ": GB, "ТИБ": TB, "ПИБ": PB, "ЭИБ": EB,
+		},
+		// Russian typography groups thousands with a thin space.
+		groupSeparator:   "\u2009",
+		decimalSeparator: ",",
+		conjunction:      "и",
+		pluralFunc:       russianPlural,
+	},
+	// Hindi text usually keeps the Latin abbreviations, so only the long
+	// units are written in Devanagari.
+	LocaleHI: {
+		longUnits: map[ByteSize]string{
+			B:  "बाइट",
+			KB: "किलोबाइट",
+			MB: "मेगाबाइट",
+			GB: "गीगाबाइट",
+			TB: "टेराबाइट",
+			PB: "पेटाबाइट",
+			EB: "एक्साबाइट",
+		},
+		shortUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "KB",
+			MB: "MB",
+			GB: "GB",
+			TB: "TB",
+			PB: "PB",
+			EB: "EB",
+		},
+		iecUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "KiB",
+			MB: "MiB",
+			GB: "GiB",
+			TB: "TiB",
+			PB: "PiB",
+			EB: "EiB",
+		},
+		siUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "kB",
+			MB: "MB",
+			GB: "GB",
+			TB: "TB",
+			PB: "PB",
+			EB: "EB",
+		},
+		parseMap: map[string]ByteSize{
+			"बाइट": B, "बी": B,
+			"किलोबाइट": KB, "केबी": KB,
+			"मेगाबाइट": MB, "एमबी": MB,
+			"गीगाबाइट": GB, "जीबी": GB,
+			"टेराबाइट": TB, "टीबी": TB,
+			"पेटाबाइट": PB, "पीबी": PB,
+			"एक्साबाइट": EB, "ईबी": EB,
+		},
+		groupSeparator:   ",",
+		decimalSeparator: ".",
+		conjunction:      "और",
+	},
+	LocaleDE: {
+		longUnits: map[ByteSize]string{
+			B:  "Byte",
+			KB: "Kilobyte",
+			MB: "Megabyte",
+			GB: "Gigabyte",
+			TB: "Terabyte",
+			PB: "Petabyte",
+			EB: "Exabyte",
+		},
+		shortUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "KB",
+			MB: "MB",
+			GB: "GB",
+			TB: "TB",
+			PB: "PB",
+			EB: "EB",
+		},
+		iecUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "KiB",
+			MB: "MiB",
+			GB: "GiB",
+			TB: "TiB",
+			PB: "PiB",
+			EB: "EiB",
+		},
+		siUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "kB",
+			MB: "MB",
+			GB: "GB",
+			TB: "TB",
+			PB: "PB",
+			EB: "EB",
+		},
+		parseMap: map[string]ByteSize{
+			"B": B, "BYTE": B, "BYTES": B,
+			"KB": KB, "KILOBYTE": KB, "KILOBYTES": KB,
+			"MB": MB, "MEGABYTE": MB, "MEGABYTES": MB,
+			"GB": GB, "GIGABYTE": GB, "GIGABYTES": GB,
+			"TB": TB, "TERABYTE": TB, "TERABYTES": TB,
+			"PB": PB, "PETABYTE": PB, "PETABYTES": PB,
+			"EB": EB, "EXABYTE": EB, "EXABYTES": EB,
+		},
+		// German groups thousands with a full stop, e.g. "1.024".
+		groupSeparator:   ".",
+		decimalSeparator: ",",
+		conjunction:      "und",
+		pluralFunc:       singularUnlessOne,
+	},
+	LocaleFR: {
+		longUnits: map[ByteSize]string{
+			B:  "octet",
+			KB: "kilo-octet",
+			MB: "méga-octet",
+			GB: "giga-octet",
+			TB: "téra-octet",
+			PB: "péta-octet",
+			EB: "exa-octet",
+		},
+		shortUnits: map[ByteSize]string{
+			B:  "o",
+			KB: "Ko",
+			MB: "Mo",
+			GB: "Go",
+			TB: "To",
+			PB: "Po",
+			EB: "Eo",
+		},
+		iecUnits: map[ByteSize]string{
+			B:  "o",
+			KB: "Kio",
+			MB: "Mio",
+			GB: "Gio",
+			TB: "Tio",
+			PB: "Pio",
+			EB: "Eio",
+		},
+		siUnits: map[ByteSize]string{
+			B:  "o",
+			KB: "ko",
+			MB: "Mo",
+			GB: "Go",
+			TB: "To",
+			PB: "Po",
+			EB: "Eo",
+		},
+		parseMap: map[string]ByteSize{
+			"O": B, "OCTET": B, "OCTETS": B,
+			"KO": KB, "KIO": KB, "KILO-OCTET": KB, "KILO-OCTETS": KB, "KILOOCTET": KB, "KILOOCTETS": KB,
+			"MO": MB, "MIO": MB, "MÉGA-OCTET": MB, "MÉGA-OCTETS": MB, "MÉGAOCTET": MB, "MÉGAOCTETS": MB,
+			"MEGA-OCTET": MB, "MEGA-OCTETS": MB, "MEGAOCTET": MB, "MEGAOCTETS": MB,
+			"GO": GB, "GIO": GB, "GIGA-OCTET": GB, "GIGA-OCTETS": GB, "GIGAOCTET": GB, "GIGAOCTETS": GB,
+			"TO": TB, "TIO": TB, "TÉRA-OCTET": TB, "TÉRA-OCTETS": TB, "TÉRAOCTET": TB, "TÉRAOCTETS": TB,
+			"TERA-OCTET": TB, "TERA-OCTETS": TB, "TERAOCTET": TB, "TERAOCTETS": TB,
+			"PO": PB, "PIO": PB, "PÉTA-OCTET": PB, "PÉTA-OCTETS": PB, "PÉTAOCTET": PB, "PÉTAOCTETS": PB,
+			"PETA-OCTET": PB, "PETA-OCTETS": PB, "PETAOCTET": PB, "PETAOCTETS": PB,
+			"EO": EB, "EIO": EB, "EXA-OCTET": EB, "EXA-OCTETS": EB, "EXAOCTET": EB, "EXAOCTETS": EB,
+		},
+		// French typography groups thousands with a narrow no-break space.
+		groupSeparator:   "\u202f",
+		decimalSeparator: ",",
+		conjunction:      "et",
+		pluralFunc:       frenchPlural,
+	},
+	LocalePL: {
+		longUnits: map[ByteSize]string{
+			B:  "bajt",
+			KB: "kilobajt",
+			MB: "megabajt",
+			GB: "gigabajt",
+			TB: "terabajt",
+			PB: "petabajt",
+			EB: "eksabajt",
+		},
+		shortUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "KB",
+			MB: "MB",
+			GB: "GB",
+			TB: "TB",
+			PB: "PB",
+			EB: "EB",
+		},
+		iecUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "KiB",
+			MB: "MiB",
+			GB: "GiB",
+			TB: "TiB",
+			PB: "PiB",
+			EB: "EiB",
+		},
+		siUnits: map[ByteSize]string{
+			B:  "B",
+			KB: "kB",
+			MB: "MB",
+			GB: "GB",
+			TB: "TB",
+			PB: "PB",
+			EB: "EB",
+		},
 		parseMap: map[string]ByteSize{
-			"Б": B, "БАЙТ": B, "БАЙТЫ": B, "БАЙТОВ": B,
-			"КБ": KB, "КИЛОБАЙТ": KB, "КИЛОБАЙТЫ": KB, "КИЛОБАЙТОВ": KB,
-			"МБ": MB, "МЕГАБАЙТ": MB, "МЕГАБАЙТЫ": MB, "МЕГАБАЙТОВ": MB,
-			"ГБ": GB, "ГИГАБАЙТ": GB, "ГИГАБАЙТЫ": GB, "ГИГАБАЙТОВ": GB,
-			"ТБ": TB, "ТЕРАБАЙТ": TB, "ТЕРАБАЙТЫ": TB, "ТЕРАБАЙТОВ": TB,
-			"ПБ": PB, "ПЕТАБАЙТ": PB, "ПЕТАБАЙТЫ": PB, "ПЕТАБАЙТОВ": PB,
-			"ЭБ": EB, "ЭКСАБАЙТ": EB, "ЭКСАБАЙТЫ": EB, "ЭКСАБАЙТОВ": EB,
+			"B": B, "BAJT": B, "BAJTA": B, "BAJTY": B, "BAJTÓW": B, "BAJTOW": B,
+			"KB": KB, "KILOBAJT": KB, "KILOBAJTA": KB, "KILOBAJTY": KB, "KILOBAJTÓW": KB, "KILOBAJTOW": KB,
+			"MB": MB, "MEGABAJT": MB, "MEGABAJTA": MB, "MEGABAJTY": MB, "MEGABAJTÓW": MB, "MEGABAJTOW": MB,
+			"GB": GB, "GIGABAJT": GB, "GIGABAJTA": GB, "GIGABAJTY": GB, "GIGABAJTÓW": GB, "GIGABAJTOW": GB,
+			"TB": TB, "TERABAJT": TB, "TERABAJTA": TB, "TERABAJTY": TB, "TERABAJTÓW": TB, "TERABAJTOW": TB,
+			"PB": PB, "PETABAJT": PB, "PETABAJTA": PB, "PETABAJTY": PB, "PETABAJTÓW": PB, "PETABAJTOW": PB,
+			"EB": EB, "EKSABAJT": EB, "EKSABAJTA": EB, "EKSABAJTY": EB, "EKSABAJTÓW": EB, "EKSABAJTOW": EB,
 		},
+		// Polish groups thousands with a no-break space.
+		groupSeparator:   "\u00a0",
+		decimalSeparator: ",",
+		conjunction:      "i",
+		pluralFunc:       polishPlural,
 	},
 }
 
 func init() {
-	// add full eng parseMap to rus
-	for k, v := range localizedUnits[LocaleEN].parseMap {
-		if _, exists := localizedUnits[LocaleRU].parseMap[k]; !exists {
-			localizedUnits[LocaleRU].parseMap[k] = v
+	// add full eng parseMap to every other locale
+	eng := unitDefinitions{parseMap: localizedUnits[LocaleEN].parseMap}
+	for locale, units := range localizedUnits {
+		if locale != LocaleEN {
+			localizedUnits[locale] = mergeDefinitions(eng, units)
 		}
 	}
 }
 
+// mergeDefinitions returns a new unitDefinitions holding every entry of base
+// and overlay, with overlay winning where both define a key. Neither argument
+// is modified.
+func mergeDefinitions(base, overlay unitDefinitions) unitDefinitions {
+	return unitDefinitions{
+		longUnits:  mergeMaps(base.longUnits, overlay.longUnits),
+		shortUnits: mergeMaps(base.shortUnits, overlay.shortUnits),
+		iecUnits:   mergeMaps(base.iecUnits, overlay.iecUnits),
+		siUnits:    mergeMaps(base.siUnits, overlay.siUnits),
+		parseMap:   mergeMaps(base.parseMap, overlay.parseMap),
+
+		groupSeparator:   mergeString(base.groupSeparator, overlay.groupSeparator),
+		decimalSeparator: mergeString(base.decimalSeparator, overlay.decimalSeparator),
+		conjunction:      mergeString(base.conjunction, overlay.conjunction),
+		pluralFunc:       mergePlural(base.pluralFunc, overlay.pluralFunc),
+	}
+}
+
+// mergePlural returns overlay if it is set and base otherwise.
+func mergePlural(base, overlay func(float64, ByteSize, unitDefinitions) string) func(float64, ByteSize, unitDefinitions) string {
+	if overlay != nil {
+		return overlay
+	}
+	return base
+}
+
+func mergeString(base, overlay string) string {
+	if overlay != "" {
+		return overlay
+	}
+	return base
+}
+
+func mergeMaps[K comparable, V any](base, overlay map[K]V) map[K]V {
+	merged := make(map[K]V, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+// defaultFormat is the initial value of Format and the fallback for formats
+// that cannot render a float64.
+const defaultFormat = "%.2f "
+
 var (
 	// CurrentLocale is the active locale for parsing and formatting.
 	CurrentLocale = LocaleEN
@@ -145,55 +518,482 @@ var (
 	// The unit will be appended at the end. Note: for compatibility with long units,
 	// this should include a trailing space when using long units.
 	// Added whitespace in the end of format string instead of original lib.
-	Format = "%.2f "
+	// A format that cannot render a single float64 falls back to the default.
+	Format = defaultFormat
+
+	// TolerateRateSuffix makes parsing ignore a trailing rate suffix such as
+	// "/s" or "/min", so "10 MB/s" parses as 10 MB. It is off by default.
+	TolerateRateSuffix = false
+
+	// AllowEmptyAsZero makes Set (and therefore UnmarshalText) treat an empty
+	// string as zero instead of an error. It is off by default.
+	AllowEmptyAsZero = false
+
+	// UseIECUnits makes short units use the unambiguous IEC spellings, e.g.
+	// "1.00 MiB" instead of "1.00 MB". Both spellings always parse. It is off
+	// by default.
+	UseIECUnits = false
+
+	// HighMagnitudeExtraDecimals is the number of extra decimals rendered for
+	// values shown in TB or larger units, where the default precision hides
+	// a lot ("15.00 EB" may be off by 5 PB). It only applies to formats with
+	// an explicit precision, such as "%.2f ". It is zero by default.
+	HighMagnitudeExtraDecimals = 0
+
+	// ParseSynonyms enables informal unit names such as "k", "meg" and "gig"
+	// when parsing. It is off by default.
+	ParseSynonyms = false
+
+	// TrimZeros drops trailing zeros after the decimal point, and the point
+	// itself if nothing is left, so "1.00 KB" becomes "1 KB" and "1.50 MB"
+	// becomes "1.5 MB". It is off by default.
+	TrimZeros = false
 )
 
+// configMu guards the configuration globals above, CurrentSystem, the unit
+// ladder and the parse synonyms. The package reads them only through
+// getters such as getLocale, or optionsFromGlobals, and their Set functions,
+// such as SetLocale or AddParseSynonym, write them under the lock. Assigning
+// the exported variables directly still works but is not safe while other
+// goroutines format or parse sizes.
+var configMu sync.RWMutex
+
+// getLocale returns CurrentLocale.
+func getLocale() Locale {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return CurrentLocale
+}
+
+// getLongUnits returns LongUnits.
+func getLongUnits() bool {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return LongUnits
+}
+
+// getFormat returns Format.
+func getFormat() string {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return Format
+}
+
+// SetLongUnits sets LongUnits. Unlike assigning the variable, it is safe to
+// call while other goroutines format sizes.
+func SetLongUnits(long bool) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	LongUnits = long
+}
+
+// SetFormat sets Format. Unlike assigning the variable, it is safe to call
+// while other goroutines format sizes.
+func SetFormat(format string) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	Format = format
+}
+
+// getTolerateRateSuffix returns TolerateRateSuffix.
+func getTolerateRateSuffix() bool {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return TolerateRateSuffix
+}
+
+// getAllowEmptyAsZero returns AllowEmptyAsZero.
+func getAllowEmptyAsZero() bool {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return AllowEmptyAsZero
+}
+
+// getUseIECUnits returns UseIECUnits.
+func getUseIECUnits() bool {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return UseIECUnits
+}
+
+// getParseSynonyms returns ParseSynonyms.
+func getParseSynonyms() bool {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return ParseSynonyms
+}
+
+// SetTolerateRateSuffix sets TolerateRateSuffix. Unlike assigning the
+// variable, it is safe to call while other goroutines parse sizes.
+func SetTolerateRateSuffix(tolerate bool) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	TolerateRateSuffix = tolerate
+}
+
+// SetAllowEmptyAsZero sets AllowEmptyAsZero. Unlike assigning the variable,
+// it is safe to call while other goroutines parse sizes.
+func SetAllowEmptyAsZero(allow bool) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	AllowEmptyAsZero = allow
+}
+
+// SetUseIECUnits sets UseIECUnits. Unlike assigning the variable, it is safe
+// to call while other goroutines format sizes.
+func SetUseIECUnits(iec bool) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	UseIECUnits = iec
+}
+
+// SetHighMagnitudeExtraDecimals sets HighMagnitudeExtraDecimals. Unlike
+// assigning the variable, it is safe to call while other goroutines format
+// sizes.
+func SetHighMagnitudeExtraDecimals(decimals int) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	HighMagnitudeExtraDecimals = decimals
+}
+
+// SetParseSynonyms sets ParseSynonyms. Unlike assigning the variable, it is
+// safe to call while other goroutines parse sizes.
+func SetParseSynonyms(enabled bool) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	ParseSynonyms = enabled
+}
+
+// SetTrimZeros sets TrimZeros. Unlike assigning the variable, it is safe to
+// call while other goroutines format sizes.
+func SetTrimZeros(trim bool) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	TrimZeros = trim
+}
+
+// parseSynonyms maps informal unit names to units. It is consulted only
+// when ParseSynonyms is enabled.
+var parseSynonyms = map[string]ByteSize{
+	"K": KB, "KILO": KB, "KILOS": KB,
+	"M": MB, "MEG": MB, "MEGS": MB,
+	"G": GB, "GIG": GB, "GIGS": GB,
+	"T": TB, "TERA": TB, "TERAS": TB,
+	"P": PB, "PETA": PB,
+	"E": EB, "EXA": EB,
+}
+
+// AddParseSynonym registers name as an informal spelling of unit. Like the
+// built-in synonyms it is only accepted while ParseSynonyms is enabled. It
+// is safe to call while other goroutines parse sizes.
+func AddParseSynonym(name string, unit ByteSize) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	parseSynonyms[strings.ToUpper(name)] = unit
+}
+
+// lookupSynonym returns the unit registered for the upper-case synonym.
+func lookupSynonym(name string) (ByteSize, bool) {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	unit, ok := parseSynonyms[name]
+	return unit, ok
+}
+
 // SetLocale sets the current locale for formatting and parsing.
 // If the locale is not supported, the current locale remains unchanged.
+// It is safe to call while other goroutines format or parse sizes.
 func SetLocale(locale Locale) {
 	if _, exists := localizedUnits[locale]; exists {
+		configMu.Lock()
 		CurrentLocale = locale
+		configMu.Unlock()
+	}
+}
+
+// AutoLocale returns the locale requested by the environment. It inspects
+// LC_ALL, LC_MESSAGES and LANG in that order and maps the language part of
+// the first non-empty value (e.g. "ru" in "ru_RU.UTF-8") to a supported
+// locale. If nothing matches, LocaleEN is returned.
+func AutoLocale() Locale {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		lang := strings.ToLower(value)
+		if i := strings.IndexAny(lang, "_.@-"); i >= 0 {
+			lang = lang[:i]
+		}
+		if _, exists := localizedUnits[Locale(lang)]; exists {
+			return Locale(lang)
+		}
+		// The first set variable wins, as it does for the C library.
+		break
 	}
+	return LocaleEN
+}
+
+// SetAutoLocale sets the current locale to the one returned by AutoLocale.
+func SetAutoLocale() {
+	SetLocale(AutoLocale())
 }
 
 // parseWithLocale parses a byte size string using the specified locale.
 func parseWithLocale(s string, locale Locale) (ByteSize, error) {
 	units, ok := localizedUnits[locale]
 	if !ok {
-		return 0, errors.New("unsupported locale: " + string(locale))
+		return 0, parseError(locale, errUnsupportedLocale, string(locale))
 	}
 
 	// Remove leading and trailing whitespace
 	s = strings.TrimSpace(s)
 
-	split := make([]string, 0)
-	for i, r := range s {
-		if !unicode.IsDigit(r) && r != '.' {
-			// Split the string by digit and size designator, remove whitespace
-			split = append(split, strings.TrimSpace(s[:i]))
-			split = append(split, strings.TrimSpace(s[i:]))
-			break
+	if getTolerateRateSuffix() {
+		s = stripRateSuffix(s)
+	}
+
+	return parseSize(s, locale, units, getSystem(), func(suffix string) (ByteSize, bool) {
+		unit, ok := units.parseMap[suffix]
+		if !ok && getParseSynonyms() {
+			unit, ok = lookupSynonym(suffix)
 		}
+		return unit, ok
+	})
+}
+
+// parseSize parses s, which has been trimmed, as a number followed by a
+// unit. lookup resolves the upper-cased unit suffix; errors are reported in
+// locale.
+func parseSize(s string, locale Locale, units unitDefinitions, system UnitSystem, lookup func(string) (ByteSize, bool)) (ByteSize, error) {
+	if !utf8.ValidString(s) {
+		return 0, parseError(locale, errInvalidEncoding, "")
+	}
+	if s == "" {
+		return 0, parseError(locale, errEmptyInput, "")
 	}
 
-	// Check to see if we split successfully
-	if len(split) != 2 {
-		return 0, errors.New("unrecognized size suffix")
+	numberPart, suffix := splitUnit(s)
+	if suffix == "" {
+		return 0, parseError(locale, errMissingSuffix, "")
 	}
 
-	// Check for unit in the parse map
-	unit, ok := units.parseMap[strings.ToUpper(split[1])]
+	unit, ok := lookup(strings.ToUpper(suffix))
 	if !ok {
-		return 0, errors.New("unrecognized size suffix: " + split[1])
+		return 0, parseError(locale, errUnknownSuffix, suffix)
+	}
+
+	if strings.HasPrefix(numberPart, "-") {
+		return 0, parseError(locale, errNegativeSize, strconv.Quote(numberPart))
+	}
+	numberPart = strings.TrimPrefix(numberPart, "+")
+
+	groupSep, decimalSep := numberSeparators(numberPart, units)
+	number, rest := splitNumber(numberPart, groupSep, decimalSep)
+	if rest != "" {
+		return 0, parseError(locale, errInvalidNumber, strconv.Quote(numberPart))
+	}
+
+	bytesize, err := scaleNumber(number, system.parsedUnitBytes(unit, suffix, units))
+	if err != nil && !errors.Is(err, ErrOverflow) {
+		return 0, parseError(locale, errInvalidNumber, strconv.Quote(number))
+	}
+	return bytesize, err
+}
+
+// splitUnit splits s at the trailing run of letters that names its unit,
+// e.g. "1.5 KiB" into "1.5" and "KiB" or "1024bytes" into "1024" and
+// "bytes". The unit is matched as a whole, so glued and multi-character
+// units cannot be split in the wrong place. A hyphen between letters, as in
+// French "kilo-octet", is part of the unit.
+func splitUnit(s string) (number string, unit string) {
+	i := len(s)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		if r == '-' && i < len(s) && i-size > 0 {
+			if before, _ := utf8.DecodeLastRuneInString(s[:i-size]); unicode.IsLetter(before) {
+				i -= size
+				continue
+			}
+		}
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) {
+			break
+		}
+		i -= size
+	}
+	return strings.TrimSpace(s[:i]), s[i:]
+}
+
+// spaceSeparators are the spaces accepted between digit groups in every
+// locale, as in "1 024 KB" pasted from a spreadsheet.
+var spaceSeparators = []string{" ", "\u00a0", "\u202f", "\u2009"}
+
+// numberSeparators returns the group and decimal separators to read number
+// with. They are the locale's, unless number is unmistakably grouped the
+// other way round: "1,048,576" or "1,000.5" in a locale with a decimal comma,
+// or "1.048.576" or "1.000,5" in one with a decimal point. A single
+// separator, as in "1,000", always takes the locale's meaning.
+func numberSeparators(number string, units unitDefinitions) (group string, decimal string) {
+	group, decimal = units.groupSeparator, units.decimalSeparator
+	if decimal == "" {
+		decimal = "."
+	}
+	switch {
+	case group != "," && isGrouped(number, ',', '.'):
+		return ",", "."
+	case group != "." && isGrouped(number, '.', ','):
+		return ".", ","
+	}
+	return group, decimal
+}
+
+// isGrouped reports whether s is a number with at least two group
+// separators, or one followed by a decimal separator, between groups of
+// three digits, e.g. "1,048,576" or "1,000.5" for ',' and '.'.
+func isGrouped(s string, group, decimal byte) bool {
+	i := 0
+	for i < len(s) && i < 3 && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return false
+	}
+	groups := 0
+	for i < len(s) && s[i] == group && isDigitGroup(s[i+1:]) {
+		groups++
+		i += 4
+	}
+	if i < len(s) && s[i] == decimal && groups > 0 {
+		j := i + 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		return j > i+1 && j == len(s)
+	}
+	return groups >= 2 && i == len(s)
+}
+
+// splitNumber splits s into its leading number and the rest. The number may
+// contain a decimal point, an exponent ("1.5e3") and, between groups of three
+// digits, groupSep or a space; group separators are dropped from the
+// returned number. The decimal point may be "." or decimalSep, as in Russian
+// "1,5"; it is returned as ".".
+func splitNumber(s string, groupSep string, decimalSep string) (number string, rest string) {
+	var sb strings.Builder
+	seenDot, seenExp := false, false
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			sb.WriteByte(c)
+			i++
+		case !seenDot && !seenExp && sb.Len() > 0 && groupSeparatorLen(s[i:], groupSep) > 0:
+			// Checked before the decimal point so that a "." group
+			// separator, as in German "1.024", is not taken for one.
+			i += groupSeparatorLen(s[i:], groupSep)
+		case c == '.' && !seenDot && !seenExp:
+			seenDot = true
+			sb.WriteByte(c)
+			i++
+		case decimalSep != "" && decimalSep != "." && !seenDot && !seenExp && strings.HasPrefix(s[i:], decimalSep):
+			seenDot = true
+			sb.WriteByte('.')
+			i += len(decimalSep)
+		case (c == 'e' || c == 'E') && !seenExp && sb.Len() > 0 && isExponent(s[i+1:]):
+			seenExp = true
+			sb.WriteByte(c)
+			i++
+			if s[i] == '+' || s[i] == '-' {
+				sb.WriteByte(s[i])
+				i++
+			}
+		default:
+			return sb.String(), s[i:]
+		}
+	}
+	return sb.String(), ""
+}
+
+// groupSeparatorLen returns the length of the group separator, groupSep or
+// a space, that s starts with if three digits follow it, or 0 if there is
+// none.
+func groupSeparatorLen(s string, groupSep string) int {
+	if groupSep != "" && strings.HasPrefix(s, groupSep) && isDigitGroup(s[len(groupSep):]) {
+		return len(groupSep)
+	}
+	for _, sep := range spaceSeparators {
+		if strings.HasPrefix(s, sep) && isDigitGroup(s[len(sep):]) {
+			return len(sep)
+		}
+	}
+	return 0
+}
+
+// isExponent reports whether s starts with the digits of an exponent,
+// optionally signed.
+func isExponent(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// isDigitGroup reports whether s starts with exactly three digits.
+func isDigitGroup(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return len(s) == 3 || s[3] < '0' || s[3] > '9'
+}
+
+// scaleNumber returns number times unit. Whole numbers are multiplied
+// exactly so that large byte counts keep full precision.
+func scaleNumber(number string, unit ByteSize) (ByteSize, error) {
+	if number != "" && strings.Trim(number, "0123456789") == "" {
+		if n, err := strconv.ParseUint(number, 10, 64); err == nil {
+			if total, err := TotalFor(unit, n); err == nil {
+				return total, nil
+			}
+		}
+		// Too large for exact arithmetic; the float path below decides
+		// whether it is just a rounded maximum or a real overflow.
 	}
 
-	value, err := strconv.ParseFloat(split[0], 64)
+	value, err := strconv.ParseFloat(number, 64)
 	if err != nil {
 		return 0, err
 	}
 
-	bytesize := ByteSize(value * float64(unit))
-	return bytesize, nil
+	bytes := value * float64(unit)
+	if bytes >= math.MaxUint64 {
+		// A rounded figure such as "16.00 EB" names a value just past the
+		// top of the range; accept it if its rounding interval reaches it.
+		if bytes-roundingError(number)*float64(unit) <= math.MaxUint64 {
+			return math.MaxUint64, nil
+		}
+		return 0, ErrOverflow
+	}
+	return ByteSize(bytes), nil
+}
+
+// roundingError returns half the place value of the last digit of number,
+// e.g. 0.005 for "16.00" and 5e15 for "18.45e18".
+func roundingError(number string) float64 {
+	mantissa, exp := number, 0
+	if i := strings.IndexAny(number, "eE"); i >= 0 {
+		mantissa = number[:i]
+		exp, _ = strconv.Atoi(number[i+1:])
+	}
+	decimals := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		decimals = len(mantissa) - i - 1
+	}
+	return 0.5 * math.Pow(10, float64(exp-decimals))
 }
 
 // Parse parses a byte size string. A byte size string is a number followed by
@@ -202,23 +1002,241 @@ func parseWithLocale(s string, locale Locale) (ByteSize, error) {
 // format of units, such as "kilobyte" or "kilobytes".
 // For Russian locale, Russian units are also supported: "Б", "КБ", "МБ", etc.
 func Parse(s string) (ByteSize, error) {
-	return parseWithLocale(s, CurrentLocale)
+	return parseWithLocale(s, getLocale())
 }
 
-// ParseWithLocale parses a byte size string using the specified locale.
+// ParseWithLocale parses a byte size string using the specified locale,
+// without reading or changing CurrentLocale. It returns an error for
+// unsupported locales.
 func ParseWithLocale(s string, locale Locale) (ByteSize, error) {
 	return parseWithLocale(s, locale)
 }
 
-// Set parses s and sets the value of b.
-// It implements the flag.Value interface.
-func (b *ByteSize) Set(s string) error {
-	bs, err := Parse(s)
+// ParseLocale is another name for ParseWithLocale.
+func ParseLocale(s string, locale Locale) (ByteSize, error) { return ParseWithLocale(s, locale) }
+
+// MustParse is like Parse but panics if s cannot be parsed. It simplifies
+// the initialization of package-level variables from constant strings.
+func MustParse(s string) ByteSize {
+	b, err := Parse(s)
 	if err != nil {
-		return err
+		panic("bytesize: Parse(" + strconv.Quote(s) + "): " + err.Error())
 	}
-	*b = bs
-	return nil
+	return b
+}
+
+// MustParseLocale is like ParseWithLocale but panics if s cannot be parsed.
+func MustParseLocale(s string, locale Locale) ByteSize {
+	b, err := ParseWithLocale(s, locale)
+	if err != nil {
+		panic("bytesize: ParseWithLocale(" + strconv.Quote(s) + ", " + string(locale) + "): " + err.Error())
+	}
+	return b
+}
+
+// ParseUnit parses s like Parse, except that a bare unit such as "MB" or
+// "kilobyte" stands for one of that unit.
+func ParseUnit(s string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(s)
+	if number, unit := splitUnit(trimmed); number == "" && unit != "" {
+		return Parse("1 " + trimmed)
+	}
+	return Parse(s)
+}
+
+// LooksLikeSize reports whether s could be a byte size string: it starts with
+// a digit and ends with a unit suffix known to the current locale. It is a
+// cheap pre-filter and does not guarantee that Parse will succeed.
+func LooksLikeSize(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return false
+	}
+
+	// Split the unit off the way Parse does, so that marks such as the
+	// Devanagari vowel signs and hyphenated units count as part of it.
+	_, unit := splitUnit(s)
+	if unit == "" {
+		return false
+	}
+
+	_, ok := localizedUnits[getLocale()].parseMap[strings.ToUpper(unit)]
+	return ok
+}
+
+// ParseMultiplied parses a byte size string of the form "<count> x <size>",
+// such as "3 x 1GB" or "2×512MB", and returns count times size. The
+// multiplication sign may be "x", "X" or "×"; count must be a whole number.
+func ParseMultiplied(s string) (ByteSize, error) {
+	i := strings.IndexAny(s, "xX×")
+	if i < 0 {
+		return 0, errors.New("missing multiplication sign")
+	}
+	_, width := utf8.DecodeRuneInString(s[i:])
+
+	countStr := strings.TrimSpace(s[:i])
+	sizeStr := strings.TrimSpace(s[i+width:])
+	if countStr == "" {
+		return 0, errors.New("missing multiplier")
+	}
+	if sizeStr == "" {
+		return 0, errors.New("missing size")
+	}
+
+	count, err := strconv.ParseUint(countStr, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	size, err := Parse(sizeStr)
+	if err != nil {
+		return 0, err
+	}
+
+	return TotalFor(size, count)
+}
+
+// ParsePercentOf parses a percentage such as "25%" or "12.5 %" and returns
+// that fraction of total, rounded to the nearest byte. The percentage must
+// not be negative; values over 100% are allowed and return ErrOverflow if
+// the result does not fit in a ByteSize.
+func ParsePercentOf(s string, total ByteSize) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	number, ok := strings.CutSuffix(s, "%")
+	if !ok {
+		return 0, errors.New("missing percent sign")
+	}
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return 0, errors.New("missing percentage")
+	}
+
+	pct, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, err
+	}
+	if pct < 0 || math.IsNaN(pct) || math.IsInf(pct, 0) {
+		return 0, fmt.Errorf("invalid percentage %q", number)
+	}
+
+	bytes := math.Round(float64(total) * pct / 100)
+	if bytes >= math.MaxUint64 {
+		return 0, ErrOverflow
+	}
+	return ByteSize(bytes), nil
+}
+
+// ParseMemoryPercent parses a memory setting such as a cache size flag. A
+// value ending in "%", such as "25%", is that fraction of totalRAM, which
+// the caller detects however it likes; it must not exceed 100%. Any other
+// value is parsed with Parse, so "512 MB" keeps working.
+func ParseMemoryPercent(s string, totalRAM ByteSize) (ByteSize, error) {
+	if !strings.HasSuffix(strings.TrimSpace(s), "%") {
+		return Parse(s)
+	}
+	size, err := ParsePercentOf(s, totalRAM)
+	if err != nil {
+		return 0, err
+	}
+	if size > totalRAM {
+		return 0, fmt.Errorf("percentage %q exceeds 100%% of memory", strings.TrimSpace(s))
+	}
+	return size, nil
+}
+
+// CompareParsed parses a and b and returns -1, 0 or +1 depending on whether
+// a is smaller than, equal to or larger than b, so "1 MB" and "1024 KB"
+// compare equal. The error names the input that failed to parse.
+func CompareParsed(a, b string) (int, error) {
+	x, err := Parse(a)
+	if err != nil {
+		return 0, fmt.Errorf("first size %q: %w", a, err)
+	}
+	y, err := Parse(b)
+	if err != nil {
+		return 0, fmt.Errorf("second size %q: %w", b, err)
+	}
+	switch {
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// TotalFor returns the space needed for count items of itemSize each, or
+// ErrOverflow if the total does not fit in a ByteSize.
+func TotalFor(itemSize ByteSize, count uint64) (ByteSize, error) {
+	if itemSize != 0 && count > uint64(math.MaxUint64/itemSize) {
+		return 0, ErrOverflow
+	}
+	return itemSize * ByteSize(count), nil
+}
+
+// Add returns b + other, or ErrOverflow if the sum does not fit in a
+// ByteSize. The largest ByteSize is math.MaxUint64 bytes, just under 16 EB.
+func (b ByteSize) Add(other ByteSize) (ByteSize, error) {
+	sum := b + other
+	if sum < b {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// Sub returns b - other, or ErrUnderflow if other is larger than b.
+func (b ByteSize) Sub(other ByteSize) (ByteSize, error) {
+	if other > b {
+		return 0, ErrUnderflow
+	}
+	return b - other, nil
+}
+
+// Mul returns b * factor, or ErrOverflow if the product does not fit in a
+// ByteSize. The largest ByteSize is math.MaxUint64 bytes, just under 16 EB.
+func (b ByteSize) Mul(factor uint64) (ByteSize, error) {
+	return TotalFor(b, factor)
+}
+
+// ExtractParenthetical parses log output of the form "1610612736 (1.5 GB)".
+// It prefers the human readable size inside the parentheses and falls back
+// to the leading number, taken as bytes, when that does not parse. It
+// reports false if neither yields a size.
+func ExtractParenthetical(s string) (ByteSize, bool) {
+	s = strings.TrimSpace(s)
+	if open := strings.IndexByte(s, '('); open >= 0 {
+		if end := strings.IndexByte(s[open:], ')'); end > 0 {
+			if b, err := Parse(s[open+1 : open+end]); err == nil {
+				return b, true
+			}
+		}
+	}
+
+	digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	if digits == 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s[:digits], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return ByteSize(n), true
+}
+
+// Set parses s and sets the value of b. If AllowEmptyAsZero is enabled, an
+// empty or blank s sets b to zero.
+// It implements the flag.Value interface.
+func (b *ByteSize) Set(s string) error {
+	if getAllowEmptyAsZero() && strings.TrimSpace(s) == "" {
+		*b = 0
+		return nil
+	}
+	bs, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*b = bs
+	return nil
 }
 
 // Type returns the type name for b.
@@ -231,6 +1249,18 @@ func (b *ByteSize) UnmarshalText(text []byte) error {
 	return b.Set(string(text))
 }
 
+// MarshalText returns the string form of b using the package global options
+// when that form parses back to exactly b, and the exact byte count such as
+// "1234 B" otherwise, so that UnmarshalText always restores the original
+// value. It implements the encoding.TextMarshaler interface.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	s := b.String()
+	if parsed, err := Parse(s); err == nil && parsed == b {
+		return []byte(s), nil
+	}
+	return []byte(strconv.FormatUint(uint64(b), 10) + " B"), nil
+}
+
 // Get returns the value of b.
 // It implements the flag.Getter interface.
 func (b ByteSize) Get() interface{} { return b }
@@ -240,74 +1270,348 @@ func New(s float64) ByteSize {
 	return ByteSize(s)
 }
 
+// Bytes returns b as a number of bytes.
+func (b ByteSize) Bytes() uint64 { return uint64(b) }
+
+// Float64 returns b as a floating-point number of bytes, for exporting to
+// metrics libraries such as Prometheus. Sizes above 2^53 bytes lose
+// precision.
+func (b ByteSize) Float64() float64 { return float64(b) }
+
+// FromFloat64 returns f bytes rounded to the nearest byte. Negative values
+// and NaN yield zero, and values beyond the range of ByteSize saturate at
+// math.MaxUint64.
+func FromFloat64(f float64) ByteSize {
+	switch {
+	case !(f > 0):
+		return 0
+	case f >= math.MaxUint64:
+		return math.MaxUint64
+	}
+	return ByteSize(math.Round(f))
+}
+
+// Kilobytes returns b as a floating-point number of KB.
+func (b ByteSize) Kilobytes() float64 { return float64(b) / float64(KB) }
+
+// Megabytes returns b as a floating-point number of MB.
+func (b ByteSize) Megabytes() float64 { return float64(b) / float64(MB) }
+
+// Gigabytes returns b as a floating-point number of GB.
+func (b ByteSize) Gigabytes() float64 { return float64(b) / float64(GB) }
+
+// Terabytes returns b as a floating-point number of TB.
+func (b ByteSize) Terabytes() float64 { return float64(b) / float64(TB) }
+
+// Petabytes returns b as a floating-point number of PB.
+func (b ByteSize) Petabytes() float64 { return float64(b) / float64(PB) }
+
+// Exabytes returns b as a floating-point number of EB.
+func (b ByteSize) Exabytes() float64 { return float64(b) / float64(EB) }
+
 // Format returns a string representation of b using the given format, unit, and unit style.
+// It is a shorthand for FormatOpts with the other options taken from the
+// package globals. An IEC unit such as "KiB" is always binary and shown as
+// written, whatever the unit system.
 func (b ByteSize) Format(format string, unit string, longUnits bool) string {
-	return b.formatWithLocale(format, unit, longUnits, CurrentLocale)
+	opts := optionsFromGlobals()
+	opts.Format, opts.LongUnits = format, longUnits
+	if unit != "" {
+		units, ok := localizedUnits[opts.Locale]
+		if !ok {
+			units = localizedUnits[LocaleEN]
+		}
+		if opts.ForcedUnit, ok = units.parseMap[strings.ToUpper(unit)]; !ok {
+			return "Unrecognized unit: " + unit
+		}
+		// IEC spellings name binary multiples in either unit system, as
+		// they do when parsed, and keep their label.
+		if opts.ForcedUnit != B && strings.EqualFold(unit, units.iecUnits[opts.ForcedUnit]) {
+			opts.UnitSystem, opts.IECUnits = SystemBinary, true
+		}
+	}
+	return b.FormatOpts(opts)
 }
 
-// formatWithLocale returns a string representation using the specified locale.
-func (b ByteSize) formatWithLocale(format string, unit string, longUnits bool, locale Locale) string {
-	units, ok := localizedUnits[locale]
-	if !ok {
-		locale = LocaleEN
-		units = localizedUnits[LocaleEN]
+// String returns the string form of b using the package global options
+func (b ByteSize) String() string {
+	return b.FormatOpts(optionsFromGlobals())
+}
+
+// In returns the string form of b expressed in unit, one of the constants
+// B, KB, …, EB, instead of the automatically selected one, e.g. "0.50 MB"
+// for (512 * KB).In(MB). It uses the current Format, locale and unit
+// settings. Any other unit is treated as B.
+func (b ByteSize) In(unit ByteSize) string {
+	opts := optionsFromGlobals()
+	opts.ForcedUnit = B
+	for _, u := range allUnits {
+		if unit == u {
+			opts.ForcedUnit = unit
+		}
 	}
+	return b.FormatOpts(opts)
+}
 
-	return b.formatWithUnits(format, unit, longUnits, units)
+// HumanReadable returns b in the largest unit in which it is at least 1,
+// with at most two decimals and no trailing zeros, e.g. "1 KB" or
+// "1.5 MB". Unit names follow the current locale and unit settings.
+func (b ByteSize) HumanReadable() string {
+	opts := optionsFromGlobals()
+	opts.Format = defaultFormat
+	opts.TrimZeros = true
+	return b.FormatOpts(opts)
 }
 
-// String returns the string form of b using the package global options
-func (b ByteSize) String() string {
-	return b.stringWithLocale(CurrentLocale)
+// FitWidth returns b formatted in at most maxChars characters, for fixed
+// width columns. It starts from String's unit with two decimals, drops
+// decimals and then moves to larger units until the result fits, never
+// showing a non-zero size as zero. If no such form fits, the space before
+// the unit is dropped, and as a last resort the result is cut off.
+func (b ByteSize) FitWidth(maxChars int) string {
+	if maxChars <= 0 {
+		return ""
+	}
+
+	opts := optionsFromGlobals()
+	start := b.autoUnitIn(opts.UnitSystem)
+	var shortest string
+	for _, space := range []string{" ", ""} {
+		for _, unit := range getUnitLadder() {
+			if unit < start {
+				continue
+			}
+			opts.ForcedUnit = unit
+			for decimals := 2; decimals >= 0; decimals-- {
+				if b != 0 && isZeroDisplay(b, unit, decimals, opts.UnitSystem) {
+					continue
+				}
+				opts.Format = "%." + strconv.Itoa(decimals) + "f" + space
+				out := b.FormatOpts(opts)
+				if utf8.RuneCountInString(out) <= maxChars {
+					return out
+				}
+				if shortest == "" || utf8.RuneCountInString(out) < utf8.RuneCountInString(shortest) {
+					shortest = out
+				}
+			}
+		}
+	}
+	return string([]rune(shortest)[:maxChars])
 }
 
-// stringWithLocale returns the string form using the specified locale
-func (b ByteSize) stringWithLocale(locale Locale) string {
-	return b.formatWithLocale(Format, "", LongUnits, locale)
+// isZeroDisplay reports whether b shown in unit with the given number of
+// decimals rounds to zero.
+func isZeroDisplay(b ByteSize, unit ByteSize, decimals int, system UnitSystem) bool {
+	value := float64(b) / float64(system.unitBytes(unit))
+	return value < 0.5*math.Pow(10, -float64(decimals))
 }
 
-func (b ByteSize) formatWithUnits(format string, unit string, longUnits bool, units unitDefinitions) string {
-	var unitSize ByteSize
-	if unit != "" {
-		var ok bool
-		unitSize, ok = units.parseMap[strings.ToUpper(unit)]
-		if !ok {
-			return "Unrecognized unit: " + unit
+// Labeled returns the string form of b followed by the unit system it is
+// expressed in, e.g. "1.00 KB (binary)" or "1.00 kB (decimal)".
+func (b ByteSize) Labeled() string {
+	return b.String() + " (" + getSystem().String() + ")"
+}
+
+// sanitizeFormat returns format, or defaultFormat if format cannot render a
+// float64.
+func sanitizeFormat(format string) string {
+	if !isFloatFormat(format) {
+		return defaultFormat
+	}
+	return format
+}
+
+// ValidateConfig checks that the package globals describe a usable
+// configuration: CurrentLocale must be supported and Format must render a
+// size that Parse accepts. It returns an error naming the offending global,
+// so applications can fail fast at startup.
+func ValidateConfig() error {
+	locale, format := getLocale(), getFormat()
+	if _, ok := localizedUnits[locale]; !ok {
+		return fmt.Errorf("bytesize: CurrentLocale %q is not supported", locale)
+	}
+	if !isFloatFormat(format) {
+		return fmt.Errorf("bytesize: Format %q must contain exactly one floating-point verb", format)
+	}
+	sentinel := 3 * GB / 2
+	if _, err := Parse(sentinel.String()); err != nil {
+		return fmt.Errorf("bytesize: Format %q renders %q, which does not parse: %v", format, sentinel.String(), err)
+	}
+	return nil
+}
+
+// addPrecision returns format with the explicit precision of its verb
+// increased by extra. format must satisfy isFloatFormat; a verb without an
+// explicit precision is left unchanged.
+func addPrecision(format string, extra int) string {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
 		}
-	} else {
-		switch {
-		case b >= EB:
-			unitSize = EB
-		case b >= PB:
-			unitSize = PB
-		case b >= TB:
-			unitSize = TB
-		case b >= GB:
-			unitSize = GB
-		case b >= MB:
-			unitSize = MB
-		case b >= KB:
-			unitSize = KB
-		default:
-			unitSize = B
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789", format[j]) >= 0 {
+			j++
 		}
+		if j == len(format) || format[j] != '.' {
+			return format
+		}
+		start := j + 1
+		end := start
+		for end < len(format) && format[end] >= '0' && format[end] <= '9' {
+			end++
+		}
+		precision, _ := strconv.Atoi(format[start:end])
+		return format[:start] + strconv.Itoa(precision+extra) + format[end:]
 	}
+	return format
+}
 
-	value := float64(b) / float64(unitSize)
+// isFloatFormat reports whether format contains exactly one verb and that
+// verb formats a float64 in decimal, e.g. "%.2f " or "%6.1g". Literal "%%"
+// sequences are allowed.
+func isFloatFormat(format string) bool {
+	verbs := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		// Skip flags, width and precision.
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i == len(format) || strings.IndexByte("eEfFgGv", format[i]) < 0 {
+			return false
+		}
+		verbs++
+	}
+	return verbs == 1
+}
 
-	if longUnits {
-		unitStr := units.longUnits[unitSize]
-		if CurrentLocale == LocaleRU {
-			unitStr = getRussianPlural(value, unitSize)
-		} else if CurrentLocale == LocaleEN {
-			if value > 0 && value != 1 {
-				unitStr += "s"
-			}
+// shortUnitName returns the short name of unit: in SI spelling in the
+// decimal system, otherwise in IEC spelling if iec is set.
+func shortUnitName(unit ByteSize, units unitDefinitions, system UnitSystem, iec bool) string {
+	if system == SystemDecimal {
+		return units.siUnits[unit]
+	}
+	if iec {
+		return units.iecUnits[unit]
+	}
+	return units.shortUnits[unit]
+}
+
+// longUnitName returns the long name of unit with the plural rules of its
+// locale applied for value, which should be the number as displayed.
+func longUnitName(value float64, unit ByteSize, units unitDefinitions) string {
+	if units.pluralFunc == nil {
+		// Hindi unit names are English loanwords and stay invariant
+		// ("1 मेगाबाइट", "5 मेगाबाइट").
+		return units.longUnits[unit]
+	}
+	return units.pluralFunc(value, unit, units)
+}
+
+// singularUnlessOne adds "s" to the unit name for every value but 1, which
+// is the rule of both English and German: "0 bytes", "1 byte",
+// "1.50 bytes", "5 Kilobytes".
+func singularUnlessOne(value float64, unit ByteSize, forms unitDefinitions) string {
+	if value != 1 {
+		return forms.longUnits[unit] + "s"
+	}
+	return forms.longUnits[unit]
+}
+
+// frenchPlural treats 0 and 1, including fractions below 2, as singular:
+// "1,5 kilo-octet", "2 kilo-octets".
+func frenchPlural(value float64, unit ByteSize, forms unitDefinitions) string {
+	if value >= 2 {
+		return forms.longUnits[unit] + "s"
+	}
+	return forms.longUnits[unit]
+}
+
+// russianPlural picks one of the three Russian forms with getRussianPlural.
+func russianPlural(value float64, unit ByteSize, _ unitDefinitions) string {
+	return getRussianPlural(value, unit)
+}
+
+// polishPlural picks one of the Polish forms with getPolishPlural.
+func polishPlural(value float64, unit ByteSize, _ unitDefinitions) string {
+	return getPolishPlural(value, unit)
+}
+
+// MagnitudeUnit returns the largest unit constant that n bytes is at least
+// one of in the current unit system. Unlike the unit String picks, it does
+// not depend on the unit ladder.
+func MagnitudeUnit(n uint64) ByteSize {
+	return largestUnit(ByteSize(n), allUnits, getSystem())
+}
+
+// autoUnit returns the largest unit of the ladder that b is at least one of
+// in the current unit system. It is meant for display only.
+func (b ByteSize) autoUnit() ByteSize {
+	return b.autoUnitIn(getSystem())
+}
+
+// autoUnitIn is like autoUnit for the given unit system.
+func (b ByteSize) autoUnitIn(system UnitSystem) ByteSize {
+	return largestUnit(b, getUnitLadder(), system)
+}
+
+// largestUnit returns the largest of units, which are in ascending order,
+// that b is at least one of in system, or the smallest if there is none.
+func largestUnit(b ByteSize, units []ByteSize, system UnitSystem) ByteSize {
+	for i := len(units) - 1; i > 0; i-- {
+		if b >= system.unitBytes(units[i]) {
+			return units[i]
+		}
+	}
+	return units[0]
+}
+
+// SetUnitLadder restricts the units chosen automatically when formatting to
+// units, e.g. {B, MB, GB}. A size is shown in the largest unit of the ladder
+// not exceeding it, or in the smallest unit if it is below all of them, so
+// 2 KB with that ladder renders as "2048.00 B". units must be known unit
+// constants in strictly ascending order; otherwise the ladder remains
+// unchanged. An empty units restores the full ladder.
+func SetUnitLadder(units []ByteSize) {
+	if len(units) == 0 {
+		configMu.Lock()
+		unitLadder = allUnits
+		configMu.Unlock()
+		return
+	}
+	next := 0
+	for _, unit := range units {
+		for next < len(allUnits) && allUnits[next] != unit {
+			next++
+		}
+		if next == len(allUnits) {
+			return
 		}
-		return fmt.Sprintf(format+"%s", value, unitStr)
+		next++
 	}
+	ladder := append([]ByteSize(nil), units...)
+	configMu.Lock()
+	unitLadder = ladder
+	configMu.Unlock()
+}
 
-	return fmt.Sprintf(format+"%s", value, units.shortUnits[unitSize])
+// getUnitLadder returns the unit ladder. SetUnitLadder replaces the slice
+// rather than modifying it, so the result may be used without the lock.
+func getUnitLadder() []ByteSize {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return unitLadder
 }
 
 // getRussianPlural returns the correct Russian plural form based on the number
@@ -345,3 +1649,686 @@ func getRussianPlural(value float64, unit ByteSize) string {
 		return forms[2] // много (0, 5-9)
 	}
 }
+
+// getPolishPlural returns the correct Polish plural form based on the number
+// as displayed. Fractions take the genitive singular, as in
+// "1,5 kilobajta"; a value rounded to a whole number, such as 1025 bytes in
+// "%.0f", takes the form of that number.
+func getPolishPlural(value float64, unit ByteSize) string {
+	intValue := int(value)
+
+	var forms []string
+	switch unit {
+	case B:
+		forms = []string{"bajt", "bajty", "bajtów"}
+	case KB:
+		forms = []string{"kilobajt", "kilobajty", "kilobajtów"}
+	case MB:
+		forms = []string{"megabajt", "megabajty", "megabajtów"}
+	case GB:
+		forms = []string{"gigabajt", "gigabajty", "gigabajtów"}
+	case TB:
+		forms = []string{"terabajt", "terabajty", "terabajtów"}
+	case PB:
+		forms = []string{"petabajt", "petabajty", "petabajtów"}
+	case EB:
+		forms = []string{"eksabajt", "eksabajty", "eksabajtów"}
+	}
+
+	switch {
+	case value != math.Trunc(value):
+		return forms[0] + "a" // ułamek (1,5 kilobajta)
+	case intValue == 1:
+		return forms[0] // jeden
+	case intValue%10 >= 2 && intValue%10 <= 4 && (intValue%100 < 12 || intValue%100 > 14):
+		return forms[1] // kilka (2-4, 22-24, ...)
+	default:
+		return forms[2] // wiele (0, 5-21, 25-31, ...)
+	}
+}
+
+// DefaultPageSize is the page size used by Pages and PagesExact when the
+// given page size is zero.
+const DefaultPageSize = 4 * KB
+
+// Pages returns the number of pages of pageSize needed to hold b, rounding
+// up. A zero pageSize is treated as DefaultPageSize.
+func (b ByteSize) Pages(pageSize ByteSize) uint64 {
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	pages := uint64(b / pageSize)
+	if b%pageSize != 0 {
+		pages++
+	}
+	return pages
+}
+
+// PagesExact returns the number of whole pages of pageSize contained in b,
+// rounding down. A zero pageSize is treated as DefaultPageSize.
+func (b ByteSize) PagesExact(pageSize ByteSize) uint64 {
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	return uint64(b / pageSize)
+}
+
+// DefaultSectorSize is the sector size used by Sectors and FromSectors when
+// the given sector size is zero.
+const DefaultSectorSize = 512 * B
+
+// Sectors returns the number of disk sectors of sectorSize needed to hold
+// b, rounding up. A zero sectorSize is treated as DefaultSectorSize.
+func (b ByteSize) Sectors(sectorSize ByteSize) uint64 {
+	if sectorSize == 0 {
+		sectorSize = DefaultSectorSize
+	}
+	return b.Pages(sectorSize)
+}
+
+// FromSectors returns the size of n disk sectors of sectorSize. A zero
+// sectorSize is treated as DefaultSectorSize. Sizes that do not fit in a
+// ByteSize saturate.
+func FromSectors(n uint64, sectorSize ByteSize) ByteSize {
+	if sectorSize == 0 {
+		sectorSize = DefaultSectorSize
+	}
+	total, err := TotalFor(sectorSize, n)
+	if err != nil {
+		return math.MaxUint64
+	}
+	return total
+}
+
+// BlockWaste returns the space b occupies when stored in whole blocks of
+// block bytes, the padding wasted in the last block, and that padding as a
+// percentage of the space used. A zero block is treated as DefaultPageSize.
+// A padded size that does not fit in a ByteSize saturates.
+func (b ByteSize) BlockWaste(block ByteSize) (used ByteSize, waste ByteSize, wastePct float64) {
+	if block == 0 {
+		block = DefaultPageSize
+	}
+	used = b
+	if rem := b % block; rem != 0 {
+		used += block - rem
+		if used < b {
+			used = math.MaxUint64
+		}
+	}
+	waste = used - b
+	if used > 0 {
+		wastePct = float64(waste) / float64(used) * 100
+	}
+	return used, waste, wastePct
+}
+
+// ClampReason limits b to the range [min, max] and reports why it was
+// changed: "below minimum", "above maximum", or "" if b was already in
+// range. If min is greater than max the bounds are swapped.
+func (b ByteSize) ClampReason(min, max ByteSize) (ByteSize, string) {
+	if min > max {
+		min, max = max, min
+	}
+	switch {
+	case b < min:
+		return min, "below minimum"
+	case b > max:
+		return max, "above maximum"
+	default:
+		return b, ""
+	}
+}
+
+// Engineering returns b in engineering notation, such as "1.61e9 B": the
+// mantissa is in [1, 1000) and the exponent is a multiple of three.
+func (b ByteSize) Engineering() string {
+	if b == 0 {
+		return "0.00e0 B"
+	}
+
+	exp := 0
+	mantissa := float64(b)
+	for mantissa >= 1000 {
+		mantissa /= 1000
+		exp += 3
+	}
+	// Rounding to the displayed precision may carry into the next group,
+	// e.g. 999.999 -> 1000.00.
+	if math.Round(mantissa*100)/100 >= 1000 {
+		mantissa /= 1000
+		exp += 3
+	}
+	return fmt.Sprintf("%.2fe%d B", mantissa, exp)
+}
+
+// DualString returns b expressed in both the nearest binary (IEC) and the
+// nearest decimal (SI) unit, e.g. "1.00 KiB / 1.02 kB", using the package
+// global Format and the current locale.
+func (b ByteSize) DualString() string {
+	units, ok := localizedUnits[getLocale()]
+	if !ok {
+		units = localizedUnits[LocaleEN]
+	}
+
+	binUnit, decUnit, decSize := B, B, uint64(1)
+	for i, unit := range allUnits {
+		if b >= unit {
+			binUnit = unit
+		}
+		size := uint64(math.Pow(1000, float64(i)))
+		if uint64(b) >= size {
+			decUnit, decSize = unit, size
+		}
+	}
+
+	format := sanitizeFormat(getFormat())
+	return fmt.Sprintf(format+"%s / "+format+"%s",
+		float64(b)/float64(binUnit), units.iecUnits[binUnit],
+		float64(b)/float64(decSize), units.siUnits[decUnit])
+}
+
+// ISO80000 returns b in the form mandated by ISO/IEC 80000-13: the value
+// with at most two decimals and no trailing zeros, a space, and a binary
+// IEC prefix, e.g. "1 KiB" or "1.5 MiB". It ignores the package globals and
+// the current locale.
+func (b ByteSize) ISO80000() string {
+	i := 0
+	for i < len(allUnits)-1 && b >= allUnits[i+1] {
+		i++
+	}
+	value := math.Round(float64(b)/float64(allUnits[i])*100) / 100
+	// Rounding to two decimals may carry into the next unit, e.g.
+	// 1023.999 KiB -> 1024 KiB.
+	if value >= 1024 && i < len(allUnits)-1 {
+		i++
+		value = math.Round(float64(b)/float64(allUnits[i])*100) / 100
+	}
+	unit := allUnits[i]
+	return strconv.FormatFloat(value, 'f', -1, 64) + " " + localizedUnits[LocaleEN].iecUnits[unit]
+}
+
+// CompactExact returns b exactly, in the largest unit that divides it
+// evenly, e.g. "2 MB" rather than "2048 KB". Sizes that no unit divides are
+// shown in bytes. It uses the short units of the current locale.
+func (b ByteSize) CompactExact() string {
+	units, ok := localizedUnits[getLocale()]
+	if !ok {
+		units = localizedUnits[LocaleEN]
+	}
+
+	system := getSystem()
+	unit := B
+	for _, u := range allUnits {
+		if size := system.unitBytes(u); b != 0 && b%size == 0 {
+			unit = u
+		}
+	}
+	count := b / system.unitBytes(unit)
+	return strconv.FormatUint(uint64(count), 10) + " " + shortUnitName(unit, units, system, getUseIECUnits())
+}
+
+// DfStyle returns b the way "df -h" shows it, or "df -H" if decimal is
+// true: a single-letter suffix, one decimal below 10 and none above, always
+// rounded up, e.g. "9.8G" or "23G". Sizes under one kilobyte are shown as a
+// plain number of bytes. Like coreutils, the decimal kilo suffix is "k".
+func (b ByteSize) DfStyle(decimal bool) string {
+	base, letters := uint64(1024), "KMGTPE"
+	if decimal {
+		base, letters = 1000, "kMGTPE"
+	}
+	if uint64(b) < base {
+		return strconv.FormatUint(uint64(b), 10)
+	}
+
+	exp, unit := 0, base
+	for exp < len(letters)-1 && uint64(b)/unit >= base {
+		exp, unit = exp+1, unit*base
+	}
+
+	// tenths is b/unit in tenths, rounded up; b*10 may not fit in 64 bits.
+	hi, lo := bits.Mul64(uint64(b), 10)
+	tenths, rem := bits.Div64(hi, lo, unit)
+	if rem != 0 {
+		tenths++
+	}
+	if tenths < 100 {
+		return strconv.FormatUint(tenths/10, 10) + "." + strconv.FormatUint(tenths%10, 10) + letters[exp:exp+1]
+	}
+
+	whole := uint64(b) / unit
+	if uint64(b)%unit != 0 {
+		whole++
+	}
+	if whole >= base && exp < len(letters)-1 {
+		// Rounding up reached the next unit, as in "1.0G" for 1023.1M.
+		return "1.0" + letters[exp+1:exp+2]
+	}
+	return strconv.FormatUint(whole, 10) + letters[exp:exp+1]
+}
+
+// NiceRound returns b rounded to a human-friendly value: 1, 2 or 5 times a
+// power of ten in its auto-selected unit, e.g. 1.3 GB -> 1 GB and
+// 2.7 MB -> 2 MB. This suits chart axis ticks.
+func (b ByteSize) NiceRound() ByteSize {
+	if b == 0 {
+		return 0
+	}
+
+	system := getSystem()
+	unit := b.autoUnitIn(system)
+	unitBytes := float64(system.unitBytes(unit))
+	value := float64(b) / unitBytes
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+
+	// Candidate multipliers, largest first; pick the first whose lower
+	// rounding threshold the value reaches.
+	steps := []struct{ nice, threshold float64 }{{10, 7}, {5, 3}, {2, 1.5}, {1, 0}}
+	fraction := value / magnitude
+	for i, step := range steps {
+		if fraction < step.threshold {
+			continue
+		}
+		// Near the top of the EB range a rounded-up value may not fit;
+		// step down to the next smaller nice value instead.
+		for _, smaller := range steps[i:] {
+			if rounded := smaller.nice * magnitude * unitBytes; rounded < math.MaxUint64 {
+				return ByteSize(rounded)
+			}
+		}
+	}
+	return ByteSize(magnitude * unitBytes)
+}
+
+// NiceString returns both NiceRound of b and its string form using the
+// package global options.
+func (b ByteSize) NiceString() (ByteSize, string) {
+	nice := b.NiceRound()
+	return nice, nice.String()
+}
+
+// Quantize rounds b down to a multiple of step, which is useful for
+// grouping sizes into buckets. A zero step returns b unchanged.
+func (b ByteSize) Quantize(step ByteSize) ByteSize {
+	if step == 0 {
+		return b
+	}
+	return b - b%step
+}
+
+// Round returns b rounded to the nearest multiple of unit, with halves
+// rounded up, e.g. 2 MB for (1536 * KB).Round(MB). A zero unit returns b
+// unchanged. If the rounded size does not fit in a ByteSize, the largest
+// multiple of unit that does is returned.
+func (b ByteSize) Round(unit ByteSize) ByteSize {
+	if unit == 0 {
+		return b
+	}
+	if b%unit < unit-unit/2 {
+		return b.RoundDown(unit)
+	}
+	return b.RoundUp(unit)
+}
+
+// RoundUp returns the smallest multiple of unit that is not less than b. A
+// zero unit returns b unchanged. If that multiple does not fit in a
+// ByteSize, the largest multiple of unit that does is returned.
+func (b ByteSize) RoundUp(unit ByteSize) ByteSize {
+	down := b.RoundDown(unit)
+	if down == b || down > math.MaxUint64-unit {
+		return down
+	}
+	return down + unit
+}
+
+// RoundDown returns the largest multiple of unit that is not greater than
+// b. It is the same as Quantize.
+func (b ByteSize) RoundDown(unit ByteSize) ByteSize {
+	return b.Quantize(unit)
+}
+
+// Band returns the label of the band b falls into. thresholds must be in
+// ascending order and labels must have one more entry than thresholds:
+// labels[0] covers sizes below thresholds[0], labels[i] covers sizes from
+// thresholds[i-1] up to (but excluding) thresholds[i], and the last label
+// covers everything from the last threshold up.
+func (b ByteSize) Band(thresholds []ByteSize, labels []string) (string, error) {
+	if len(labels) != len(thresholds)+1 {
+		return "", fmt.Errorf("expected %d labels for %d thresholds, got %d",
+			len(thresholds)+1, len(thresholds), len(labels))
+	}
+	for i := 1; i < len(thresholds); i++ {
+		if thresholds[i] <= thresholds[i-1] {
+			return "", errors.New("thresholds must be in ascending order")
+		}
+	}
+
+	for i, threshold := range thresholds {
+		if b < threshold {
+			return labels[i], nil
+		}
+	}
+	return labels[len(labels)-1], nil
+}
+
+// SizeParts holds the pieces String would combine for a ByteSize.
+type SizeParts struct {
+	// Value is the size expressed in Unit.
+	Value float64
+	// Short is the short unit suffix, e.g. "MB".
+	Short string
+	// Long is the long unit name with plural rules applied, e.g. "megabytes".
+	Long string
+	// Unit is the auto-selected unit.
+	Unit ByteSize
+}
+
+// Parts returns the auto-selected value and unit of b along with both unit
+// forms in the current locale, for callers that lay out sizes themselves.
+func (b ByteSize) Parts() SizeParts {
+	units, ok := localizedUnits[getLocale()]
+	if !ok {
+		units = localizedUnits[LocaleEN]
+	}
+
+	system := getSystem()
+	unit := b.autoUnitIn(system)
+	value := float64(b) / float64(system.unitBytes(unit))
+	return SizeParts{
+		Value: value,
+		Short: shortUnitName(unit, units, system, getUseIECUnits()),
+		Long:  longUnitName(value, unit, units),
+		Unit:  unit,
+	}
+}
+
+// ValueUnit returns the value and unit String would show for b, separately:
+// the auto-selected value and the unit in the current locale, long if
+// LongUnits is set and short otherwise, e.g. 2 and "MB" for 2 MB. It is a
+// shorthand for Parts.
+func (b ByteSize) ValueUnit() (value float64, unit string) {
+	parts := b.Parts()
+	if getLongUnits() {
+		return parts.Value, parts.Long
+	}
+	return parts.Value, parts.Short
+}
+
+// MantissaExp decomposes b so that b == mantissa * 1024^exp with mantissa in
+// [1, 1024). For zero both results are zero.
+func (b ByteSize) MantissaExp() (mantissa float64, exp int) {
+	if b == 0 {
+		return 0, 0
+	}
+	// The binary units regardless of CurrentSystem and the unit ladder, so
+	// that b == mantissa * 1024^exp always holds.
+	for exp < len(allUnits)-1 && b >= allUnits[exp+1] {
+		exp++
+	}
+	return float64(b) / float64(allUnits[exp]), exp
+}
+
+// Lerp linearly interpolates between a and b. t is clamped to [0, 1], so
+// Lerp(a, b, 0) is a and Lerp(a, b, 1) is b.
+func Lerp(a, b ByteSize, t float64) ByteSize {
+	switch {
+	case t <= 0 || math.IsNaN(t):
+		return a
+	case t >= 1:
+		return b
+	case b >= a:
+		return a + ByteSize(math.Round(float64(b-a)*t))
+	default:
+		return a - ByteSize(math.Round(float64(a-b)*t))
+	}
+}
+
+// WeightedAverage returns the mean of sizes weighted by weights, rounded to
+// the nearest byte. It returns an error if the slices differ in length, a
+// weight is negative, NaN or infinite, the weights sum to zero, or the
+// weighted sum does not fit in a float64.
+func WeightedAverage(sizes []ByteSize, weights []float64) (ByteSize, error) {
+	if len(sizes) != len(weights) {
+		return 0, errors.New("sizes and weights differ in length")
+	}
+	var sum, total float64
+	for i, size := range sizes {
+		w := weights[i]
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return 0, fmt.Errorf("invalid weight %v", w)
+		}
+		sum += float64(size) * w
+		total += w
+	}
+	if total == 0 {
+		return 0, errors.New("total weight is zero")
+	}
+	if sum < 0 || math.IsInf(sum, 0) || math.IsInf(total, 0) {
+		return 0, fmt.Errorf("weighted sum %v is out of range", sum)
+	}
+	avg := sum / total
+	if avg >= math.MaxUint64 {
+		return 0, ErrOverflow
+	}
+	return ByteSize(math.Round(avg)), nil
+}
+
+// MeanSize returns the mean of samples, rounded to the nearest byte, and
+// their sample standard deviation formatted like String with a "±" prefix,
+// e.g. "±12.30 MB". A single sample has no spread and reports "±0.00 B".
+// For no samples it returns 0 and "".
+func MeanSize(samples []ByteSize) (mean ByteSize, stddevStr string) {
+	if len(samples) == 0 {
+		return 0, ""
+	}
+
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample)
+	}
+	avg := sum / float64(len(samples))
+
+	var variance float64
+	if len(samples) > 1 {
+		for _, sample := range samples {
+			d := float64(sample) - avg
+			variance += d * d
+		}
+		variance /= float64(len(samples) - 1)
+	}
+
+	mean = math.MaxUint64
+	if avg < math.MaxUint64 {
+		mean = ByteSize(math.Round(avg))
+	}
+	return mean, "±" + ByteSize(math.Round(math.Sqrt(variance))).String()
+}
+
+// Allocation is a range of Size bytes starting at Offset, as used by
+// UnionSize.
+type Allocation struct {
+	Offset, Size ByteSize
+}
+
+// UnionSize returns the number of bytes covered by at least one of allocs,
+// so overlapping ranges are only counted once. Ranges extending past the
+// top of the ByteSize range are cut off there.
+func UnionSize(allocs []Allocation) ByteSize {
+	sorted := append([]Allocation(nil), allocs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	var total, start, end ByteSize
+	started := false
+	for _, a := range sorted {
+		if a.Size == 0 {
+			continue
+		}
+		aEnd := a.Offset + a.Size
+		if aEnd < a.Offset {
+			aEnd = math.MaxUint64
+		}
+		switch {
+		case !started:
+			start, end, started = a.Offset, aEnd, true
+		case a.Offset > end:
+			total += end - start
+			start, end = a.Offset, aEnd
+		case aEnd > end:
+			end = aEnd
+		}
+	}
+	return total + end - start
+}
+
+// GroupedBytes returns the exact number of bytes in b with thousands grouped
+// by the current locale's separator, e.g. "1,234,567 B" in English or
+// "1 234 567 Б" (with thin spaces) in Russian.
+func (b ByteSize) GroupedBytes() string {
+	units, ok := localizedUnits[getLocale()]
+	if !ok {
+		units = localizedUnits[LocaleEN]
+	}
+	return groupDigits(strconv.FormatUint(uint64(b), 10), units.groupSeparator) + " " + units.shortUnits[B]
+}
+
+// GroupedString returns the string form of b using the package global
+// options, with the integer part of the value grouped and the decimal
+// separator of the current locale, e.g. "1,010.00 MB" in English or
+// "1 010,00 МБ" in Russian.
+func (b ByteSize) GroupedString() string {
+	opts := optionsFromGlobals()
+	opts.Grouping = true
+	return b.FormatOpts(opts)
+}
+
+// localizeNumber groups the integer digits of the first number in s with
+// the locale's group separator and replaces its decimal point with the
+// locale's decimal separator.
+func localizeNumber(s string, units unitDefinitions) string {
+	start := strings.IndexAny(s, "0123456789")
+	if start < 0 {
+		return s
+	}
+	end := start
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+
+	out := s[:start] + groupDigits(s[start:end], units.groupSeparator)
+	if rest := s[end:]; strings.HasPrefix(rest, ".") {
+		return out + units.decimalSeparator + rest[1:]
+	}
+	return out + s[end:]
+}
+
+// groupDigits inserts sep between every group of three digits in digits,
+// counting from the right.
+func groupDigits(digits string, sep string) string {
+	if len(digits) <= 3 || sep == "" {
+		return digits
+	}
+
+	var sb strings.Builder
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+	sb.WriteString(digits[:head])
+	for i := head; i < len(digits); i += 3 {
+		sb.WriteString(sep)
+		sb.WriteString(digits[i : i+3])
+	}
+	return sb.String()
+}
+
+// ToNextUnit returns how many bytes must be added to b for its auto-selected
+// unit to roll over to the next one, e.g. from 900 MB to 1 GB. For sizes
+// already shown in the largest unit it returns 0.
+func (b ByteSize) ToNextUnit() ByteSize {
+	system := getSystem()
+	unit := b.autoUnitIn(system)
+	for _, next := range getUnitLadder() {
+		if next > unit {
+			return system.unitBytes(next) - b
+		}
+	}
+	return 0
+}
+
+// EqualWithin reports whether b and o differ by at most tol bytes.
+func (b ByteSize) EqualWithin(o ByteSize, tol ByteSize) bool {
+	if b > o {
+		return b-o <= tol
+	}
+	return o-b <= tol
+}
+
+// Component is one term of a Breakdown: Count whole units of Unit.
+type Component struct {
+	Unit  ByteSize
+	Count uint64
+}
+
+// Breakdown splits b into whole units, largest first, so that the sum of
+// Unit*Count over all components equals b, e.g. 1 GB + 200 MB + 5 B. Units
+// with a zero count are omitted; zero has no components.
+func (b ByteSize) Breakdown() []Component {
+	var components []Component
+	for i := len(allUnits) - 1; i >= 0; i-- {
+		unit := allUnits[i]
+		if count := b / unit; count > 0 {
+			components = append(components, Component{Unit: unit, Count: uint64(count)})
+			b -= count * unit
+		}
+	}
+	return components
+}
+
+// ExactBreakdown returns the components of Breakdown as a map from unit to
+// count, so that summing unit*count over the map gives exactly b.
+func (b ByteSize) ExactBreakdown() map[ByteSize]uint64 {
+	components := b.Breakdown()
+	counts := make(map[ByteSize]uint64, len(components))
+	for _, c := range components {
+		counts[c.Unit] = c.Count
+	}
+	return counts
+}
+
+// BreakdownWords renders the components of Breakdown as words in locale,
+// joined with the locale's conjunction, e.g. "1 gigabyte and 200 megabytes"
+// or "1 гигабайт и 200 мегабайтов". Units with a zero count never appear.
+// If there are more than maxParts components, the remainder is rounded into
+// the last unit shown, carrying into larger units as needed, so 1 GB + 1023 MB
+// + 700 KB with two parts reads "2 gigabytes". A maxParts of zero or less
+// renders every component.
+func (b ByteSize) BreakdownWords(locale Locale, maxParts int) string {
+	units, ok := localizedUnits[locale]
+	if !ok {
+		units = localizedUnits[LocaleEN]
+	}
+
+	components := b.Breakdown()
+	if maxParts > 0 && len(components) > maxParts {
+		last := components[maxParts-1].Unit
+		shown := b - b%last
+		if b%last >= last/2 && shown+last > shown {
+			shown += last
+		}
+		// Rounding up can carry into larger units and zero out smaller
+		// ones, so the rounded size never has more than maxParts parts.
+		components = shown.Breakdown()
+	}
+	if len(components) == 0 {
+		components = []Component{{Unit: B}}
+	}
+
+	words := make([]string, len(components))
+	for i, c := range components {
+		words[i] = strconv.FormatUint(c.Count, 10) + " " + longUnitName(float64(c.Count), c.Unit, units)
+	}
+
+	if len(words) == 1 {
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " " + units.conjunction + " " + words[len(words)-1]
+}
@@ -0,0 +1,153 @@
+package bytesize
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConcurrentConfig changes the global configuration while other
+// goroutines format and parse sizes. Run it with "go test -race" to check
+// that the accessors are synchronized.
+func TestConcurrentConfig(t *testing.T) {
+	originalLocale := getLocale()
+	originalLongUnits := getLongUnits()
+	originalFormat := getFormat()
+	defer func() {
+		SetLocale(originalLocale)
+		SetLongUnits(originalLongUnits)
+		SetFormat(originalFormat)
+	}()
+
+	locales := []Locale{LocaleEN, LocaleRU, LocaleHI, LocaleDE, LocaleFR, LocalePL}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				SetLocale(locales[(i+j)%len(locales)])
+				SetLongUnits(j%2 == 0)
+				if j%3 == 0 {
+					SetFormat("%.1f ")
+				} else {
+					SetFormat(defaultFormat)
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				size := ByteSize(j) * MB
+				if size.String() == "" {
+					t.Error("String returned an empty string")
+				}
+				_, _ = Parse("1.5 GB")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestConcurrentUnitConfig is like TestConcurrentConfig for the unit
+// system, the unit ladder and the parse synonyms.
+func TestConcurrentUnitConfig(t *testing.T) {
+	originalSystem := getSystem()
+	originalSynonyms := ParseSynonyms
+	ParseSynonyms = true
+	defer func() {
+		SetUnitSystem(originalSystem)
+		SetUnitLadder(nil)
+		ParseSynonyms = originalSynonyms
+		configMu.Lock()
+		delete(parseSynonyms, "BLOCK")
+		configMu.Unlock()
+	}()
+
+	ladders := [][]ByteSize{nil, {B, MB, GB}, {KB, TB}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				SetUnitSystem(UnitSystem((i + j) % 2))
+				SetUnitLadder(ladders[(i+j)%len(ladders)])
+				AddParseSynonym("block", 4*KB)
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				size := ByteSize(j) * MB
+				if size.String() == "" {
+					t.Error("String returned an empty string")
+				}
+				_ = size.ToNextUnit()
+				_, _ = Parse("2 gig")
+				_, _ = Parse("3 block")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestConcurrentFlags is like TestConcurrentConfig for the boolean and
+// numeric formatting and parsing switches.
+func TestConcurrentFlags(t *testing.T) {
+	originalRate := TolerateRateSuffix
+	originalEmpty := AllowEmptyAsZero
+	originalIEC := UseIECUnits
+	originalDecimals := HighMagnitudeExtraDecimals
+	originalSynonyms := ParseSynonyms
+	originalTrim := TrimZeros
+	defer func() {
+		SetTolerateRateSuffix(originalRate)
+		SetAllowEmptyAsZero(originalEmpty)
+		SetUseIECUnits(originalIEC)
+		SetHighMagnitudeExtraDecimals(originalDecimals)
+		SetParseSynonyms(originalSynonyms)
+		SetTrimZeros(originalTrim)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				on := (i+j)%2 == 0
+				SetTolerateRateSuffix(on)
+				SetAllowEmptyAsZero(on)
+				SetUseIECUnits(on)
+				SetHighMagnitudeExtraDecimals(j % 3)
+				SetParseSynonyms(on)
+				SetTrimZeros(on)
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				size := ByteSize(j) * TB
+				if size.String() == "" || size.Parts().Short == "" || size.CompactExact() == "" {
+					t.Error("formatting returned an empty string")
+				}
+				_, _ = Parse("10 MB/s")
+				_, _ = Parse("2 gig")
+				var b ByteSize
+				_ = b.Set("")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,111 @@
+package bytesize
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrSkipLine is returned by ParseKeyValue for blank lines and comment lines
+// starting with "#". Callers should simply move on to the next line.
+var ErrSkipLine = errors.New("blank or comment line")
+
+// ParseKeyValue parses a configuration line of the form "key = size" or
+// "key: size", such as "max_upload = 50 MB". The key and value are trimmed
+// and the value is parsed with Parse.
+func ParseKeyValue(line string) (key string, size ByteSize, err error) {
+	key, value, err := cutKeyValue(line)
+	if err != nil {
+		return "", 0, err
+	}
+
+	size, err = Parse(value)
+	if err != nil {
+		return "", 0, err
+	}
+	return key, size, nil
+}
+
+// cutKeyValue splits a "key = value" or "key: value" line into its trimmed
+// key and value. It returns ErrSkipLine for blank and comment lines.
+func cutKeyValue(line string) (key, value string, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", ErrSkipLine
+	}
+
+	i := strings.IndexAny(line, "=:")
+	if i < 0 {
+		return "", "", errors.New("missing \"=\" or \":\" separator")
+	}
+
+	key = strings.TrimSpace(line[:i])
+	if key == "" {
+		return "", "", errors.New("missing key")
+	}
+	return key, strings.TrimSpace(line[i+1:]), nil
+}
+
+// ParseFile reads "key = size" lines from r, as ParseKeyValue does, and
+// returns the sizes of the given keys. It also accepts shell and Makefile
+// assignments such as "export CACHE=\"512 MB\"" and "CACHE := 512MB".
+//
+// If keys are given, lines for other keys and lines that are not
+// assignments are ignored, and keys missing from r are missing from the
+// result. Without keys every line must be an assignment and all of them are
+// returned. Errors carry the 1-based line number. If a key is assigned more
+// than once, the last assignment wins.
+func ParseFile(r io.Reader, keys ...string) (map[string]ByteSize, error) {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	sizes := make(map[string]ByteSize)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		key, value, err := cutKeyValue(normalizeAssignment(scanner.Text()))
+		if errors.Is(err, ErrSkipLine) {
+			continue
+		}
+		if len(keys) > 0 && (err != nil || !wanted[key]) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		size, err := Parse(unquote(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n, key, err)
+		}
+		sizes[key] = size
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sizes, nil
+}
+
+// normalizeAssignment rewrites a shell "export KEY=value" or a Makefile
+// "KEY := value" or "KEY ?= value" line to the plain "KEY = value" form.
+func normalizeAssignment(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "export ")
+	for _, op := range []string{":=", "?="} {
+		if i := strings.Index(line, op); i >= 0 && !strings.ContainsAny(line[:i], "=:") {
+			return line[:i] + "=" + line[i+len(op):]
+		}
+	}
+	return line
+}
+
+// unquote removes a pair of matching single or double quotes around s.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,134 @@
+package bytesize
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseKeyValue(t *testing.T) {
+	tests := []struct {
+		line string
+		key  string
+		size ByteSize
+		err  error
+		fail bool
+	}{
+		{"max_upload = 50 MB", "max_upload", 50 * MB, nil, false},
+		{"cache: 1.5GB", "cache", ByteSize(1.5 * float64(GB)), nil, false},
+		{"  buffer=512 KB  ", "buffer", 512 * KB, nil, false},
+		{"# max_upload = 50 MB", "", 0, ErrSkipLine, true},
+		{"   ", "", 0, ErrSkipLine, true},
+		{"max_upload 50 MB", "", 0, nil, true},
+		{"= 50 MB", "", 0, nil, true},
+		{"max_upload = fifty", "", 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			key, size, err := ParseKeyValue(tt.line)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("ParseKeyValue(%q): expected error", tt.line)
+				}
+				if tt.err != nil && !errors.Is(err, tt.err) {
+					t.Fatalf("ParseKeyValue(%q) error = %v, expected %v", tt.line, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKeyValue(%q) error = %v", tt.line, err)
+			}
+			if key != tt.key || size != tt.size {
+				t.Errorf("ParseKeyValue(%q) = (%q, %d), expected (%q, %d)", tt.line, key, size, tt.key, tt.size)
+			}
+		})
+	}
+}
+
+// failingReader returns err after the data it holds.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestParseFile(t *testing.T) {
+	const env = `# limits
+export CACHE_SIZE="512 MB"
+MAX_UPLOAD := 50MB
+BUFFER ?= 64 KB
+PATH=/usr/local/bin:/usr/bin
+
+all: build
+	go build ./...
+SWAP='2 GB'
+SWAP = 4 GB
+`
+
+	t.Run("requested keys", func(t *testing.T) {
+		sizes, err := ParseFile(strings.NewReader(env), "CACHE_SIZE", "MAX_UPLOAD", "BUFFER", "SWAP", "MISSING")
+		if err != nil {
+			t.Fatalf("ParseFile error = %v", err)
+		}
+		expected := map[string]ByteSize{
+			"CACHE_SIZE": 512 * MB,
+			"MAX_UPLOAD": 50 * MB,
+			"BUFFER":     64 * KB,
+			"SWAP":       4 * GB,
+		}
+		if len(sizes) != len(expected) {
+			t.Fatalf("ParseFile = %v, expected %v", sizes, expected)
+		}
+		for key, size := range expected {
+			if sizes[key] != size {
+				t.Errorf("ParseFile[%q] = %d, expected %d", key, sizes[key], size)
+			}
+		}
+	})
+
+	t.Run("all keys", func(t *testing.T) {
+		sizes, err := ParseFile(strings.NewReader("a = 1 KB\n\n# b = 2 KB\nc: 3 KB\n"))
+		if err != nil {
+			t.Fatalf("ParseFile error = %v", err)
+		}
+		if len(sizes) != 2 || sizes["a"] != KB || sizes["c"] != 3*KB {
+			t.Errorf("ParseFile = %v, expected map[a:1024 c:3072]", sizes)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		keys  []string
+		line  string
+	}{
+		{"bad requested value", env + "MAX_UPLOAD = lots\n", []string{"MAX_UPLOAD"}, "line 11:"},
+		{"not an assignment", "a = 1 KB\nb 2 KB\n", nil, "line 2:"},
+		{"bad value", "a = 1 KB\nb = 2 KB\nc = x\n", nil, "line 3:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFile(strings.NewReader(tt.input), tt.keys...)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.line) {
+				t.Fatalf("ParseFile error = %v, expected it to start with %q", err, tt.line)
+			}
+		})
+	}
+
+	t.Run("read error", func(t *testing.T) {
+		readErr := errors.New("disk on fire")
+		_, err := ParseFile(&failingReader{data: "a = 1 KB\n", err: readErr})
+		if !errors.Is(err, readErr) {
+			t.Fatalf("ParseFile error = %v, expected %v", err, readErr)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package bytesize
+
+import "context"
+
+// localeKey is the context key under which WithLocale stores a locale.
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying locale, for use with ParseCtx
+// and StringCtx. This lets concurrent requests use different locales
+// without changing CurrentLocale.
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale stored in ctx by WithLocale, or
+// CurrentLocale if there is none.
+func LocaleFromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(localeKey{}).(Locale); ok {
+		return locale
+	}
+	return getLocale()
+}
+
+// ParseCtx parses s like ParseWithLocale, using the locale of ctx.
+func ParseCtx(ctx context.Context, s string) (ByteSize, error) {
+	return parseWithLocale(s, LocaleFromContext(ctx))
+}
+
+// StringCtx returns the string form of b like String, but in the locale of
+// ctx.
+func (b ByteSize) StringCtx(ctx context.Context) string {
+	opts := optionsFromGlobals()
+	opts.Locale = LocaleFromContext(ctx)
+	return b.FormatOpts(opts)
+}
@@ -0,0 +1,58 @@
+package bytesize
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestContextLocale(t *testing.T) {
+	originalFormat := Format
+	originalLongUnits := LongUnits
+	defer func() {
+		Format = originalFormat
+		LongUnits = originalLongUnits
+	}()
+	Format = "%.0f "
+	LongUnits = true
+
+	ru := WithLocale(context.Background(), LocaleRU)
+	en := WithLocale(context.Background(), LocaleEN)
+
+	if s := (2 * MB).StringCtx(ru); s != "2 мегабайта" {
+		t.Errorf("StringCtx(ru) = %q", s)
+	}
+	if s := (2 * MB).StringCtx(context.Background()); s != (2 * MB).String() {
+		t.Errorf("StringCtx without a locale = %q, expected %q", s, (2 * MB).String())
+	}
+	if b, err := ParseCtx(ru, "2 ГБ"); err != nil || b != 2*GB {
+		t.Errorf("ParseCtx(ru, 2 ГБ) = %d, %v", b, err)
+	}
+	if _, err := ParseCtx(WithLocale(context.Background(), "xx"), "2 GB"); err == nil {
+		t.Error("ParseCtx accepted an unsupported locale")
+	}
+
+	// Both locales render at the same time without touching the globals.
+	var wg sync.WaitGroup
+	errs := make(chan string, 200)
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if s := (5 * MB).StringCtx(ru); s != "5 мегабайтов" {
+				errs <- s
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if s := (5 * MB).StringCtx(en); s != "5 megabytes" {
+				errs <- s
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for s := range errs {
+		t.Errorf("concurrent StringCtx rendered %q", s)
+	}
+}
@@ -1,7 +1,15 @@
 package bytesize
 
 import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func Test_Overflow(t *testing.T) {
@@ -110,6 +118,16 @@ var parseTable = []struct {
 	{"1024B", "1.00 KB", false},
 	{"1KB 1023B", "", true},
 	{"1.5GB", "1.50 GB", false},
+	{"1 Gbyte", "1.00 GB", false},
+	{"1 GBytes", "1.00 GB", false},
+	{"2 Mbytes", "2.00 MB", false},
+	{"3kbyte", "3.00 KB", false},
+	{"1MiB", "1.00 MB", false},
+	{"1024bytes", "1.00 KB", false},
+	{"2KiB", "2.00 KB", false},
+	{"1.5e3B", "1.46 KB", false},
+	{"1.2.3 MB", "", true},
+	{"1 B B", "", true},
 	{"1", "", true},
 }
 
@@ -119,6 +137,9 @@ func Test_Parse(t *testing.T) {
 		if err != nil && !v.Fail {
 			t.Fatal(err)
 		}
+		if err == nil && v.Fail {
+			t.Fatalf("Expected %q to fail, received %s", v.Input, b)
+		}
 		if b.String() != v.Result && !v.Fail {
 			t.Fatalf("Expected %s, received %s", v.Result, b)
 		}
@@ -186,3 +207,1834 @@ func Test_Math(t *testing.T) {
 		}
 	}
 }
+
+var pagesTable = []struct {
+	Size     ByteSize
+	PageSize ByteSize
+	Pages    uint64
+	Exact    uint64
+}{
+	{10000, 4 * KB, 3, 2},
+	{10000, 0, 3, 2},
+	{0, 4 * KB, 0, 0},
+	{4 * KB, 4 * KB, 1, 1},
+	{4*KB + 1, 4 * KB, 2, 1},
+	{2 * MB, 2 * MB, 1, 1},
+}
+
+func Test_Pages(t *testing.T) {
+	for _, v := range pagesTable {
+		if p := v.Size.Pages(v.PageSize); p != v.Pages {
+			t.Fatalf("Pages(%d) of %d: expected %d, received %d", v.PageSize, v.Size, v.Pages, p)
+		}
+		if p := v.Size.PagesExact(v.PageSize); p != v.Exact {
+			t.Fatalf("PagesExact(%d) of %d: expected %d, received %d", v.PageSize, v.Size, v.Exact, p)
+		}
+	}
+}
+
+var clampTable = []struct {
+	Size   ByteSize
+	Min    ByteSize
+	Max    ByteSize
+	Result ByteSize
+	Reason string
+}{
+	{512 * KB, 1 * MB, 10 * MB, 1 * MB, "below minimum"},
+	{20 * MB, 1 * MB, 10 * MB, 10 * MB, "above maximum"},
+	{5 * MB, 1 * MB, 10 * MB, 5 * MB, ""},
+	{1 * MB, 1 * MB, 10 * MB, 1 * MB, ""},
+	{10 * MB, 1 * MB, 10 * MB, 10 * MB, ""},
+	{20 * MB, 10 * MB, 1 * MB, 10 * MB, "above maximum"},
+	{512 * KB, 10 * MB, 1 * MB, 1 * MB, "below minimum"},
+}
+
+func Test_ClampReason(t *testing.T) {
+	for _, v := range clampTable {
+		result, reason := v.Size.ClampReason(v.Min, v.Max)
+		if result != v.Result || reason != v.Reason {
+			t.Fatalf("ClampReason(%s, %s) of %s: expected (%s, %q), received (%s, %q)",
+				v.Min, v.Max, v.Size, v.Result, v.Reason, result, reason)
+		}
+	}
+}
+
+var parseMultipliedTable = []struct {
+	Input  string
+	Result ByteSize
+	Fail   bool
+}{
+	{"3 x 1GB", 3 * GB, false},
+	{"2×512MB", 1 * GB, false},
+	{"4X 256 KB", 1 * MB, false},
+	{"1 x 0 B", 0, false},
+	{"x 1GB", 0, true},
+	{"3 x", 0, true},
+	{"3 1GB", 0, true},
+	{"1.5 x 1GB", 0, true},
+	{"3 x potato", 0, true},
+	{"20 x 1EB", 0, true},
+}
+
+func Test_ParseMultiplied(t *testing.T) {
+	for _, v := range parseMultipliedTable {
+		b, err := ParseMultiplied(v.Input)
+		if v.Fail {
+			if err == nil {
+				t.Fatalf("ParseMultiplied(%q): expected error, received %s", v.Input, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("ParseMultiplied(%q): expected %s, received %s", v.Input, v.Result, b)
+		}
+	}
+}
+
+var engineeringTable = []struct {
+	Size   ByteSize
+	Result string
+}{
+	{0, "0.00e0 B"},
+	{1, "1.00e0 B"},
+	{999, "999.00e0 B"},
+	{1000, "1.00e3 B"},
+	{1536, "1.54e3 B"},
+	{12345678, "12.35e6 B"},
+	{999999, "1.00e6 B"},
+	{1610612736, "1.61e9 B"},
+	{1 * EB, "1.15e18 B"},
+}
+
+func Test_Engineering(t *testing.T) {
+	for _, v := range engineeringTable {
+		if e := v.Size.Engineering(); e != v.Result {
+			t.Fatalf("Engineering() of %d: expected %s, received %s", uint64(v.Size), v.Result, e)
+		}
+	}
+}
+
+var looksLikeSizeTable = []struct {
+	Input  string
+	Locale Locale
+	Result bool
+}{
+	{"1B", LocaleEN, true},
+	{" 1.5 GB ", LocaleEN, true},
+	{"10 megabytes", LocaleEN, true},
+	{"1KB 1023B", LocaleEN, true},
+	{"hello", LocaleEN, false},
+	{"", LocaleEN, false},
+	{"1024", LocaleEN, false},
+	{"MB", LocaleEN, false},
+	{"1 potato", LocaleEN, false},
+	{"1 GB/s", LocaleEN, false},
+	{"3 केबी", LocaleHI, true},
+	{"3 किलोबाइट", LocaleHI, true},
+	{"3 आलू", LocaleHI, false},
+	{"2 kilo-octets", LocaleFR, true},
+	{"1,5 méga-octet", LocaleFR, true},
+	{"2 kilo-", LocaleFR, false},
+}
+
+func Test_LooksLikeSize(t *testing.T) {
+	originLocale := CurrentLocale
+	defer func() {
+		CurrentLocale = originLocale
+	}()
+
+	for _, v := range looksLikeSizeTable {
+		SetLocale(v.Locale)
+		if r := LooksLikeSize(v.Input); r != v.Result {
+			t.Fatalf("LooksLikeSize(%q) in %s: expected %t, received %t", v.Input, v.Locale, v.Result, r)
+		}
+	}
+}
+
+var whitespaceTable = []struct {
+	Input  string
+	Locale Locale
+	Result ByteSize
+}{
+	{"1\t\tMB", LocaleEN, MB},
+	{"1   MB", LocaleEN, MB},
+	{"1 \t MB", LocaleEN, MB},
+	{"1 MB", LocaleEN, MB},
+	{"\t1.5\tGB\t", LocaleEN, ByteSize(1.5 * float64(GB))},
+	{"1  байт", LocaleRU, 1},
+	{"2\t\tКБ", LocaleRU, 2 * KB},
+}
+
+func Test_ParseWhitespace(t *testing.T) {
+	for _, v := range whitespaceTable {
+		b, err := ParseWithLocale(v.Input, v.Locale)
+		if err != nil {
+			t.Fatalf("ParseWithLocale(%q, %s): %v", v.Input, v.Locale, err)
+		}
+		if b != v.Result {
+			t.Fatalf("ParseWithLocale(%q, %s): expected %d, received %d", v.Input, v.Locale, v.Result, b)
+		}
+	}
+}
+
+func Test_Labeled(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+	}()
+
+	Format = "%.2f "
+	LongUnits = false
+	if l := KB.Labeled(); l != "1.00 KB (binary)" {
+		t.Fatalf("Expected %s, received %s", "1.00 KB (binary)", l)
+	}
+}
+
+func Test_BySize(t *testing.T) {
+	sizes := []ByteSize{GB, 1, MB, 0, KB, 512 * KB}
+	sort.Sort(BySize(sizes))
+	expected := []ByteSize{0, 1, KB, 512 * KB, MB, GB}
+	for i := range expected {
+		if sizes[i] != expected[i] {
+			t.Fatalf("Expected %v, received %v", expected, sizes)
+		}
+	}
+
+	sizes = []ByteSize{GB, 1, MB}
+	sort.Slice(sizes, func(i, j int) bool { return Less(sizes[i], sizes[j]) })
+	if !sort.IsSorted(BySize(sizes)) {
+		t.Fatalf("sort.Slice with Less did not sort: %v", sizes)
+	}
+}
+
+var dualStringTable = []struct {
+	Size   ByteSize
+	Result string
+}{
+	{1024, "1.00 KiB / 1.02 kB"},
+	{1000, "1000.00 B / 1.00 kB"},
+	{1, "1.00 B / 1.00 B"},
+	{MB, "1.00 MiB / 1.05 MB"},
+	{EB, "1.00 EiB / 1.15 EB"},
+}
+
+func Test_DualString(t *testing.T) {
+	originFormat := Format
+	defer func() { Format = originFormat }()
+	Format = "%.2f "
+
+	for _, v := range dualStringTable {
+		if d := v.Size.DualString(); d != v.Result {
+			t.Fatalf("DualString() of %d: expected %s, received %s", uint64(v.Size), v.Result, d)
+		}
+	}
+}
+
+var synonymTable = []struct {
+	Input  string
+	Result ByteSize
+}{
+	{"5 gig", 5 * GB},
+	{"5 gigs", 5 * GB},
+	{"512 meg", 512 * MB},
+	{"2 k", 2 * KB},
+	{"2k", 2 * KB},
+	{"1 tera", TB},
+}
+
+func Test_ParseSynonyms(t *testing.T) {
+	originSynonyms := ParseSynonyms
+	defer func() { ParseSynonyms = originSynonyms }()
+
+	ParseSynonyms = false
+	for _, v := range synonymTable {
+		if _, err := Parse(v.Input); err == nil {
+			t.Fatalf("Parse(%q) with ParseSynonyms off: expected error", v.Input)
+		}
+	}
+
+	ParseSynonyms = true
+	for _, v := range synonymTable {
+		b, err := Parse(v.Input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("Parse(%q): expected %s, received %s", v.Input, v.Result, b)
+		}
+	}
+
+	AddParseSynonym("mebi", MB)
+	defer delete(parseSynonyms, "MEBI")
+	if b, err := Parse("3 mebi"); err != nil || b != 3*MB {
+		t.Fatalf("Parse(%q): expected %s, received %s (%v)", "3 mebi", 3*MB, b, err)
+	}
+}
+
+var totalForTable = []struct {
+	ItemSize ByteSize
+	Count    uint64
+	Result   ByteSize
+	Fail     bool
+}{
+	{3 * MB, 1000, 3000 * MB, false},
+	{0, math.MaxUint64, 0, false},
+	{GB, 0, 0, false},
+	{1, math.MaxUint64, math.MaxUint64, false},
+	{EB, 15, 15 * EB, false},
+	{EB, 16, 0, true},
+	{2, math.MaxUint64, 0, true},
+}
+
+func Test_TotalFor(t *testing.T) {
+	for _, v := range totalForTable {
+		total, err := TotalFor(v.ItemSize, v.Count)
+		if v.Fail {
+			if !errors.Is(err, ErrOverflow) {
+				t.Fatalf("TotalFor(%d, %d): expected ErrOverflow, received %v", v.ItemSize, v.Count, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != v.Result {
+			t.Fatalf("TotalFor(%d, %d): expected %d, received %d", v.ItemSize, v.Count, v.Result, total)
+		}
+	}
+}
+
+var niceTable = []struct {
+	Size   ByteSize
+	Nice   ByteSize
+	Result string
+}{
+	{0, 0, "0.00 B"},
+	{1, 1, "1.00 B"},
+	{130 * MB / 100 * 1024, GB, "1.00 GB"},
+	{27 * MB / 10, 2 * MB, "2.00 MB"},
+	{4 * KB, 5 * KB, "5.00 KB"},
+	{700 * MB, 1000 * MB, "1000.00 MB"},
+	{123 * GB, 100 * GB, "100.00 GB"},
+	{15 * EB, 10 * EB, "10.00 EB"},
+}
+
+func Test_NiceString(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+	}()
+	Format = "%.2f "
+	LongUnits = false
+
+	for _, v := range niceTable {
+		nice, s := v.Size.NiceString()
+		if nice != v.Nice || s != v.Result {
+			t.Fatalf("NiceString() of %d: expected (%d, %s), received (%d, %s)", uint64(v.Size), v.Nice, v.Result, nice, s)
+		}
+		if nice != v.Size.NiceRound() {
+			t.Fatalf("NiceString() and NiceRound() disagree for %d", uint64(v.Size))
+		}
+	}
+}
+
+func Test_Quantize(t *testing.T) {
+	sizes := []ByteSize{0, 1, 99 * MB, 100 * MB, 150 * MB, 199*MB + 1023*KB, 200 * MB, GB}
+	buckets := map[ByteSize]int{}
+	for _, s := range sizes {
+		buckets[s.Quantize(100*MB)]++
+	}
+
+	expected := map[ByteSize]int{0: 3, 100 * MB: 3, 200 * MB: 1, 1000 * MB: 1}
+	if len(buckets) != len(expected) {
+		t.Fatalf("Expected %d buckets, received %v", len(expected), buckets)
+	}
+	for bucket, count := range expected {
+		if buckets[bucket] != count {
+			t.Fatalf("Bucket %s: expected %d sizes, received %d", bucket, count, buckets[bucket])
+		}
+	}
+
+	if q := (123 * MB).Quantize(0); q != 123*MB {
+		t.Fatalf("Quantize(0): expected %s, received %s", 123*MB, q)
+	}
+}
+
+var bandTable = []struct {
+	Size   ByteSize
+	Result string
+}{
+	{0, "empty"},
+	{512 * MB, "empty"},
+	{1 * GB, "low"},
+	{9 * GB, "low"},
+	{10 * GB, "medium"},
+	{99 * GB, "medium"},
+	{100 * GB, "high"},
+	{EB, "high"},
+}
+
+func Test_Band(t *testing.T) {
+	thresholds := []ByteSize{GB, 10 * GB, 100 * GB}
+	labels := []string{"empty", "low", "medium", "high"}
+	for _, v := range bandTable {
+		band, err := v.Size.Band(thresholds, labels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if band != v.Result {
+			t.Fatalf("Band() of %s: expected %s, received %s", v.Size, v.Result, band)
+		}
+	}
+
+	if _, err := GB.Band(thresholds, labels[:3]); err == nil {
+		t.Fatal("Band() with too few labels did not fail")
+	}
+	if _, err := GB.Band([]ByteSize{10 * GB, GB}, labels[:3]); err == nil {
+		t.Fatal("Band() with unsorted thresholds did not fail")
+	}
+}
+
+var badFormatTable = []string{
+	"%x ",
+	"%d ",
+	"%s ",
+	"%q",
+	"%f %f ",
+	"no verb ",
+	"%*f ",
+	"%",
+}
+
+func Test_BadFormat(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+	}()
+	LongUnits = false
+
+	for _, f := range badFormatTable {
+		Format = f
+		if s := (3 * MB / 2).String(); s != "1.50 MB" {
+			t.Fatalf("Format %q: expected fallback %q, received %q", f, "1.50 MB", s)
+		}
+		if s := KB.Format(f, "kb", false); s != "1.00 KB" {
+			t.Fatalf("Format(%q): expected fallback %q, received %q", f, "1.00 KB", s)
+		}
+	}
+
+	for f, expected := range map[string]string{"%5.1f ": "  1.5 MB", "%g": "1.5MB", "%.0f%% ": "2% MB", "%e ": "1.500000e+00 MB"} {
+		Format = f
+		if s := (3 * MB / 2).String(); s != expected {
+			t.Fatalf("Format %q: expected %q, received %q", f, expected, s)
+		}
+	}
+}
+
+var mantissaExpTable = []struct {
+	Size     ByteSize
+	Mantissa float64
+	Exp      int
+}{
+	{0, 0, 0},
+	{1, 1, 0},
+	{1000, 1000, 0},
+	{1023, 1023, 0},
+	{1024, 1, 1},
+	{1536, 1.5, 1},
+	{5 * MB, 5, 2},
+	{3 * EB, 3, 6},
+}
+
+func Test_MantissaExp(t *testing.T) {
+	for _, v := range mantissaExpTable {
+		m, e := v.Size.MantissaExp()
+		if m != v.Mantissa || e != v.Exp {
+			t.Fatalf("MantissaExp() of %d: expected (%v, %d), received (%v, %d)", uint64(v.Size), v.Mantissa, v.Exp, m, e)
+		}
+		if v.Size != 0 && ByteSize(m*math.Pow(1024, float64(e))) != v.Size {
+			t.Fatalf("MantissaExp() of %d does not recompose", uint64(v.Size))
+		}
+	}
+
+	// The decomposition is binary in the decimal system too.
+	originSystem := CurrentSystem
+	defer func() { CurrentSystem = originSystem }()
+	SetUnitSystem(SystemDecimal)
+	for _, v := range mantissaExpTable {
+		m, e := v.Size.MantissaExp()
+		if m != v.Mantissa || e != v.Exp {
+			t.Fatalf("MantissaExp() of %d in the decimal system: expected (%v, %d), received (%v, %d)", uint64(v.Size), v.Mantissa, v.Exp, m, e)
+		}
+	}
+}
+
+var lerpTable = []struct {
+	A, B   ByteSize
+	T      float64
+	Result ByteSize
+}{
+	{0, GB, 0, 0},
+	{0, GB, 0.5, 512 * MB},
+	{0, GB, 1, GB},
+	{0, GB, -1, 0},
+	{0, GB, 2, GB},
+	{0, GB, math.NaN(), 0},
+	{GB, 0, 0.25, 768 * MB},
+	{10, 20, 0.5, 15},
+}
+
+func Test_Lerp(t *testing.T) {
+	for _, v := range lerpTable {
+		if r := Lerp(v.A, v.B, v.T); r != v.Result {
+			t.Fatalf("Lerp(%s, %s, %v): expected %s, received %s", v.A, v.B, v.T, v.Result, r)
+		}
+	}
+}
+
+var magnitudeUnitTable = []struct {
+	Bytes uint64
+	Unit  ByteSize
+}{
+	{0, B},
+	{1023, B},
+	{1024, KB},
+	{1<<20 - 1, KB},
+	{1 << 20, MB},
+	{1<<30 - 1, MB},
+	{1 << 30, GB},
+	{1<<40 - 1, GB},
+	{1 << 40, TB},
+	{1<<50 - 1, TB},
+	{1 << 50, PB},
+	{1<<60 - 1, PB},
+	{1 << 60, EB},
+	{math.MaxUint64, EB},
+}
+
+func Test_MagnitudeUnit(t *testing.T) {
+	for _, v := range magnitudeUnitTable {
+		if u := MagnitudeUnit(v.Bytes); u != v.Unit {
+			t.Fatalf("MagnitudeUnit(%d): expected %d, received %d", v.Bytes, v.Unit, u)
+		}
+	}
+}
+
+func Test_LadderIndependence(t *testing.T) {
+	defer SetUnitLadder(nil)
+
+	for _, ladder := range [][]ByteSize{{B, GB}, {MB}, {KB, TB, EB}} {
+		SetUnitLadder(ladder)
+		for _, v := range magnitudeUnitTable {
+			if u := MagnitudeUnit(v.Bytes); u != v.Unit {
+				t.Fatalf("MagnitudeUnit(%d) with ladder %v: expected %d, received %d", v.Bytes, ladder, v.Unit, u)
+			}
+		}
+		for _, v := range mantissaExpTable {
+			if m, e := v.Size.MantissaExp(); m != v.Mantissa || e != v.Exp {
+				t.Fatalf("MantissaExp() of %d with ladder %v: expected (%v, %d), received (%v, %d)", uint64(v.Size), ladder, v.Mantissa, v.Exp, m, e)
+			}
+		}
+	}
+}
+
+var parseRangeTable = []struct {
+	Input  string
+	Result ByteSize
+	Fail   bool
+}{
+	{"18446744073709551615 B", math.MaxUint64, false},
+	{"16.00 EB", math.MaxUint64, false},
+	{"16 EB", math.MaxUint64, false},
+	{"17 EB", 0, true},
+	{"16.01 EB", 0, true},
+	{"18446744073709551617000 B", 0, true},
+}
+
+func Test_ParseRange(t *testing.T) {
+	for _, v := range parseRangeTable {
+		b, err := Parse(v.Input)
+		if v.Fail {
+			if !errors.Is(err, ErrOverflow) {
+				t.Fatalf("Parse(%q): expected ErrOverflow, received %d, %v", v.Input, b, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("Parse(%q): expected %d, received %d", v.Input, v.Result, b)
+		}
+	}
+}
+
+var toNextUnitTable = []struct {
+	Size   ByteSize
+	Result ByteSize
+}{
+	{0, KB},
+	{1000, 24},
+	{KB, MB - KB},
+	{900 * MB, 124 * MB},
+	{GB + GB/2, 1024*GB - GB - GB/2},
+	{PB, 1023 * PB},
+	{EB, 0},
+	{math.MaxUint64, 0},
+}
+
+func Test_ToNextUnit(t *testing.T) {
+	for _, v := range toNextUnitTable {
+		if r := v.Size.ToNextUnit(); r != v.Result {
+			t.Fatalf("ToNextUnit() of %s: expected %d, received %d", v.Size, v.Result, r)
+		}
+	}
+}
+
+var equalWithinTable = []struct {
+	A, B   ByteSize
+	Tol    ByteSize
+	Result bool
+}{
+	{MB, MB + 1, 0, false},
+	{MB, MB + 1, 10, true},
+	{MB + 1, MB, 0, false},
+	{MB + 1, MB, 10, true},
+	{MB, MB, 0, true},
+	{0, math.MaxUint64, math.MaxUint64, true},
+	{math.MaxUint64, 0, math.MaxUint64 - 1, false},
+}
+
+func Test_EqualWithin(t *testing.T) {
+	for _, v := range equalWithinTable {
+		if r := v.A.EqualWithin(v.B, v.Tol); r != v.Result {
+			t.Fatalf("(%d).EqualWithin(%d, %d): expected %t, received %t", v.A, v.B, v.Tol, v.Result, r)
+		}
+	}
+}
+
+func Test_SetEmpty(t *testing.T) {
+	originAllowEmpty := AllowEmptyAsZero
+	defer func() { AllowEmptyAsZero = originAllowEmpty }()
+
+	for _, input := range []string{"", "  "} {
+		b := GB
+		AllowEmptyAsZero = false
+		if err := b.Set(input); err == nil || b != GB {
+			t.Fatalf("Set(%q) with AllowEmptyAsZero off: expected error and unchanged value, received %s, %v", input, b, err)
+		}
+
+		AllowEmptyAsZero = true
+		if err := b.Set(input); err != nil || b != 0 {
+			t.Fatalf("Set(%q) with AllowEmptyAsZero on: expected 0, received %s, %v", input, b, err)
+		}
+	}
+
+	AllowEmptyAsZero = true
+	var b ByteSize
+	if err := b.Set("1 MB"); err != nil || b != MB {
+		t.Fatalf("Set(%q): expected %s, received %s, %v", "1 MB", MB, b, err)
+	}
+	if err := b.Set("potato"); err == nil {
+		t.Fatal("Set(\"potato\") with AllowEmptyAsZero on: expected error")
+	}
+}
+
+func Test_Breakdown(t *testing.T) {
+	components := (GB + 200*MB + 5).Breakdown()
+	expected := []Component{{GB, 1}, {MB, 200}, {B, 5}}
+	if len(components) != len(expected) {
+		t.Fatalf("Expected %v, received %v", expected, components)
+	}
+	for i := range expected {
+		if components[i] != expected[i] {
+			t.Fatalf("Expected %v, received %v", expected, components)
+		}
+	}
+
+	if components := ByteSize(0).Breakdown(); len(components) != 0 {
+		t.Fatalf("Breakdown() of 0: expected no components, received %v", components)
+	}
+}
+
+var extractParentheticalTable = []struct {
+	Input    string
+	Expected ByteSize
+	OK       bool
+}{
+	{"1610612736 (1.5 GB)", 3 * GB / 2, true},
+	{"  1610612736 (1.5GB)  ", 3 * GB / 2, true},
+	{"(512 KB)", 512 * KB, true},
+	{"1610612736 (garbage)", 1610612736, true},
+	{"2048", 2048, true},
+	{"2048 (", 2048, true},
+	{"(nope)", 0, false},
+	{"", 0, false},
+	{"99999999999999999999 (x)", 0, false},
+}
+
+func Test_ExtractParenthetical(t *testing.T) {
+	for _, v := range extractParentheticalTable {
+		b, ok := ExtractParenthetical(v.Input)
+		if b != v.Expected || ok != v.OK {
+			t.Fatalf("ExtractParenthetical(%q): expected %d, %t, received %d, %t", v.Input, v.Expected, v.OK, b, ok)
+		}
+	}
+}
+
+func Test_WeightedAverage(t *testing.T) {
+	avg, err := WeightedAverage([]ByteSize{KB, 3 * KB}, []float64{1, 1})
+	if err != nil || avg != 2*KB {
+		t.Fatalf("Expected %d, received %d (%v)", 2*KB, avg, err)
+	}
+
+	avg, err = WeightedAverage([]ByteSize{KB, 4 * KB}, []float64{2, 1})
+	if err != nil || avg != 2*KB {
+		t.Fatalf("Expected %d, received %d (%v)", 2*KB, avg, err)
+	}
+
+	avg, err = WeightedAverage([]ByteSize{10, 11}, []float64{1, 1})
+	if err != nil || avg != 11 {
+		t.Fatalf("Expected rounding to 11, received %d (%v)", avg, err)
+	}
+
+	if _, err := WeightedAverage([]ByteSize{KB}, []float64{1, 2}); err == nil {
+		t.Fatal("Expected an error for mismatched lengths")
+	}
+	if _, err := WeightedAverage([]ByteSize{KB, MB}, []float64{0, 0}); err == nil {
+		t.Fatal("Expected an error for zero total weight")
+	}
+	if _, err := WeightedAverage(nil, nil); err == nil {
+		t.Fatal("Expected an error for no sizes")
+	}
+	for _, weights := range [][]float64{
+		{-1, 2}, {1, -0.5}, {math.NaN(), 1}, {math.Inf(1), 1}, {math.Inf(-1), 1},
+		{math.MaxFloat64, math.MaxFloat64},
+	} {
+		if avg, err := WeightedAverage([]ByteSize{KB, EB}, weights); err == nil {
+			t.Fatalf("Expected an error for weights %v, received %d", weights, avg)
+		}
+	}
+}
+
+func Test_MeanSize(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	originLocale := CurrentLocale
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+		CurrentLocale = originLocale
+	}()
+	Format = "%.2f "
+	LongUnits = false
+	CurrentLocale = LocaleEN
+
+	mean, stddev := MeanSize([]ByteSize{10 * MB, 20 * MB, 30 * MB})
+	if mean != 20*MB || stddev != "±10.00 MB" {
+		t.Fatalf("Expected %d ±10.00 MB, received %d %s", 20*MB, mean, stddev)
+	}
+
+	mean, stddev = MeanSize([]ByteSize{2 * KB, 4 * KB, 4 * KB, 4 * KB, 5 * KB, 5 * KB, 7 * KB, 9 * KB})
+	if mean != 5*KB || stddev != "±2.14 KB" {
+		t.Fatalf("Expected %d ±2.14 KB, received %d %s", 5*KB, mean, stddev)
+	}
+
+	mean, stddev = MeanSize([]ByteSize{GB})
+	if mean != GB || stddev != "±0.00 B" {
+		t.Fatalf("Expected %d ±0.00 B for one sample, received %d %s", GB, mean, stddev)
+	}
+
+	mean, stddev = MeanSize([]ByteSize{math.MaxUint64, math.MaxUint64})
+	if mean != math.MaxUint64 {
+		t.Fatalf("Expected the mean to saturate, received %d %s", mean, stddev)
+	}
+
+	mean, stddev = MeanSize(nil)
+	if mean != 0 || stddev != "" {
+		t.Fatalf("Expected 0 and an empty string for no samples, received %d %q", mean, stddev)
+	}
+}
+
+func Test_HighMagnitudeExtraDecimals(t *testing.T) {
+	originFormat := Format
+	originExtra := HighMagnitudeExtraDecimals
+	defer func() {
+		Format = originFormat
+		HighMagnitudeExtraDecimals = originExtra
+	}()
+
+	Format = "%.2f "
+	size := 1536*GB + 12*GB
+	if s := size.String(); s != "1.51 TB" {
+		t.Fatalf("Expected 1.51 TB without extra decimals, received %s", s)
+	}
+
+	HighMagnitudeExtraDecimals = 2
+	if s := size.String(); s != "1.5117 TB" {
+		t.Fatalf("Expected 1.5117 TB with extra decimals, received %s", s)
+	}
+	if s := (1536 * MB).String(); s != "1.50 GB" {
+		t.Fatalf("Expected GB values to keep their precision, received %s", s)
+	}
+
+	Format = "%.f "
+	if s := size.String(); s != "1.51 TB" {
+		t.Fatalf("Expected %%.f to gain two decimals, received %s", s)
+	}
+
+	Format = "%v "
+	if s := (2 * TB).String(); s != "2 TB" {
+		t.Fatalf("Expected a format without precision to be unchanged, received %s", s)
+	}
+}
+
+func Test_SortKey(t *testing.T) {
+	sizes := []ByteSize{GB, B, 3 * KB, MB, 0}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].SortKey() < sizes[j].SortKey() })
+	expected := []ByteSize{0, B, 3 * KB, MB, GB}
+	for i := range expected {
+		if sizes[i] != expected[i] {
+			t.Fatalf("Expected %v, received %v", expected, sizes)
+		}
+	}
+	if key := (2 * KB).SortKey(); key != 2048 {
+		t.Fatalf("Expected 2048, received %d", key)
+	}
+
+	pairs := [][2]ByteSize{
+		{0, 0}, {0, 1}, {MB, MB}, {GB, KB},
+		{math.MaxUint64 - 1, math.MaxUint64}, {math.MaxUint64, math.MaxUint64}, {math.MaxUint64, 0},
+	}
+	for _, p := range pairs {
+		a, b := p[0], p[1]
+		if (a.SortKey() < b.SortKey()) != a.Less(b) || (a.SortKey() == b.SortKey()) != a.Equal(b) {
+			t.Fatalf("SortKey of %d and %d disagrees with Less and Equal", uint64(a), uint64(b))
+		}
+		if c := cmp.Compare(a.SortKey(), b.SortKey()); c != a.Compare(b) {
+			t.Fatalf("SortKey of %d and %d compares as %d, Compare returns %d", uint64(a), uint64(b), c, a.Compare(b))
+		}
+	}
+
+	mixed := []interface{ SortKey() uint64 }{
+		Rate{Amount: 10 * MB, Per: time.Second},
+		GB,
+		Rate{Amount: 60 * KB, Per: time.Minute},
+		ByteSize(0),
+		Rate{Amount: 3 * KB, Per: time.Second},
+		2 * KB,
+	}
+	sort.SliceStable(mixed, func(i, j int) bool { return mixed[i].SortKey() < mixed[j].SortKey() })
+	keys := make([]uint64, len(mixed))
+	for i, m := range mixed {
+		keys[i] = m.SortKey()
+	}
+	if expectedKeys := []uint64{0, 1024, 2048, 3072, 10485760, 1073741824}; !slices.Equal(keys, expectedKeys) {
+		t.Fatalf("Expected mixed keys %v, received %v", expectedKeys, keys)
+	}
+	if _, ok := mixed[1].(Rate); !ok {
+		t.Fatalf("Expected 1 KB/s to sort between 0 B and 2 KB, received %v", mixed)
+	}
+
+	rateKeys := []struct {
+		Rate Rate
+		Key  uint64
+	}{
+		{NewRate(600*KB, time.Minute), 10 * 1024},
+		{NewRate(10*KB, time.Second), 10 * 1024},
+		{NewRate(3, 2*time.Second), 2},
+		{NewRate(MB, 0), 0},
+		{NewRate(math.MaxUint64, time.Millisecond), math.MaxUint64},
+	}
+	for _, v := range rateKeys {
+		if key := v.Rate.SortKey(); key != v.Key {
+			t.Fatalf("SortKey() of %+v: expected %d, received %d", v.Rate, v.Key, key)
+		}
+	}
+}
+
+func Test_ValidateConfig(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	originLocale := CurrentLocale
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+		CurrentLocale = originLocale
+	}()
+
+	for _, format := range []string{"%.2f ", "%.0f", "%e ", "%8.3f "} {
+		Format = format
+		for _, long := range []bool{false, true} {
+			LongUnits = long
+			if err := ValidateConfig(); err != nil {
+				t.Fatalf("Format %q (long units %t): unexpected error %v", format, long, err)
+			}
+		}
+	}
+
+	LongUnits = false
+	for _, format := range []string{"%d ", "%s", "%.2f %.2f ", "%.2f%% "} {
+		Format = format
+		err := ValidateConfig()
+		if err == nil || !strings.Contains(err.Error(), "Format") {
+			t.Fatalf("Format %q: expected an error naming Format, received %v", format, err)
+		}
+	}
+
+	Format = "%.2f "
+	CurrentLocale = Locale("xx")
+	if err := ValidateConfig(); err == nil || !strings.Contains(err.Error(), "CurrentLocale") {
+		t.Fatalf("Expected an error naming CurrentLocale, received %v", err)
+	}
+}
+
+var blockWasteTable = []struct {
+	Size, Block, Used, Waste ByteSize
+	WastePct                 float64
+}{
+	{5000, 4 * KB, 8 * KB, 3192, 38.96484375},
+	{8 * KB, 4 * KB, 8 * KB, 0, 0},
+	{1, 0, 4 * KB, 4095, 99.9755859375},
+	{0, 4 * KB, 0, 0, 0},
+	{100, 512, 512, 412, 80.46875},
+	{math.MaxUint64, 4 * KB, math.MaxUint64, 0, 0},
+}
+
+func Test_BlockWaste(t *testing.T) {
+	for _, v := range blockWasteTable {
+		used, waste, pct := v.Size.BlockWaste(v.Block)
+		if used != v.Used || waste != v.Waste || pct != v.WastePct {
+			t.Fatalf("(%d).BlockWaste(%d): expected %d, %d, %g, received %d, %d, %g",
+				v.Size, v.Block, v.Used, v.Waste, v.WastePct, used, waste, pct)
+		}
+	}
+}
+
+func Test_SetUnitLadder(t *testing.T) {
+	originFormat := Format
+	defer func() {
+		Format = originFormat
+		SetUnitLadder(nil)
+	}()
+	Format = "%.2f "
+
+	SetUnitLadder([]ByteSize{B, MB, GB})
+	ladderTable := []struct {
+		Size     ByteSize
+		Expected string
+	}{
+		{2 * KB, "2048.00 B"},
+		{3 * MB / 2, "1.50 MB"},
+		{2 * TB, "2048.00 GB"},
+		{0, "0.00 B"},
+	}
+	for _, v := range ladderTable {
+		if s := v.Size.String(); s != v.Expected {
+			t.Fatalf("Expected %s, received %s", v.Expected, s)
+		}
+	}
+
+	SetUnitLadder([]ByteSize{MB, GB})
+	if s := (2 * KB).String(); s != "0.00 MB" {
+		t.Fatalf("Expected sizes below the ladder in its smallest unit, received %s", s)
+	}
+
+	for _, invalid := range [][]ByteSize{{GB, MB}, {MB, MB}, {B, 1000}} {
+		SetUnitLadder(invalid)
+		if s := (2 * KB).String(); s != "0.00 MB" {
+			t.Fatalf("SetUnitLadder(%v) changed the ladder: received %s", invalid, s)
+		}
+	}
+
+	SetUnitLadder(nil)
+	if s := (2 * KB).String(); s != "2.00 KB" {
+		t.Fatalf("Expected the full ladder to be restored, received %s", s)
+	}
+}
+
+var compareParsedTable = []struct {
+	A, B     string
+	Expected int
+}{
+	{"1 MB", "1024 KB", 0},
+	{"1.5 GB", "1536MB", 0},
+	{"1 KB", "1 MB", -1},
+	{"2 GB", "2047 MB", 1},
+}
+
+func Test_CompareParsed(t *testing.T) {
+	for _, v := range compareParsedTable {
+		c, err := CompareParsed(v.A, v.B)
+		if err != nil || c != v.Expected {
+			t.Fatalf("CompareParsed(%q, %q): expected %d, received %d (%v)", v.A, v.B, v.Expected, c, err)
+		}
+	}
+
+	if _, err := CompareParsed("1 QB", "1 MB"); err == nil || !strings.Contains(err.Error(), "first") {
+		t.Fatalf("Expected an error naming the first input, received %v", err)
+	}
+	if _, err := CompareParsed("1 MB", "MB"); err == nil || !strings.Contains(err.Error(), "second") {
+		t.Fatalf("Expected an error naming the second input, received %v", err)
+	}
+}
+
+func Test_IECUnits(t *testing.T) {
+	originFormat := Format
+	originUseIECUnits := UseIECUnits
+	defer func() {
+		Format = originFormat
+		UseIECUnits = originUseIECUnits
+	}()
+
+	iecTable := []struct {
+		Input    string
+		Expected ByteSize
+	}{
+		{"1 KiB", KB},
+		{"1 MiB", MB},
+		{"1.5GiB", 3 * GB / 2},
+		{"2 tib", 2 * TB},
+		{"1 PiB", PB},
+		{"1 EiB", EB},
+	}
+	for _, v := range iecTable {
+		b, err := Parse(v.Input)
+		if err != nil || b != v.Expected {
+			t.Fatalf("Parse(%q): expected %d, received %d (%v)", v.Input, v.Expected, b, err)
+		}
+	}
+
+	Format = "%.2f"
+	UseIECUnits = true
+	if s := MB.String(); s != "1.00MiB" {
+		t.Fatalf("Expected 1.00MiB, received %s", s)
+	}
+	if s := ByteSize(512).String(); s != "512.00B" {
+		t.Fatalf("Expected 512.00B, received %s", s)
+	}
+	if p := GB.Parts(); p.Short != "GiB" {
+		t.Fatalf("Expected Parts().Short to be GiB, received %s", p.Short)
+	}
+}
+
+var iso80000Table = []struct {
+	Size     ByteSize
+	Expected string
+}{
+	{0, "0 B"},
+	{512, "512 B"},
+	{1024, "1 KiB"},
+	{1572864, "1.5 MiB"},
+	{1610612736, "1.5 GiB"},
+	{1234567, "1.18 MiB"},
+	{5 * PB, "5 PiB"},
+	{math.MaxUint64, "16 EiB"},
+	{1023, "1023 B"},
+	{1048570, "1023.99 KiB"},
+	{1048575, "1 MiB"},
+	{GB - 1, "1 GiB"},
+	{EB - 1, "1 EiB"},
+}
+
+func Test_ISO80000(t *testing.T) {
+	originLocale := CurrentLocale
+	defer func() { CurrentLocale = originLocale }()
+
+	for _, locale := range []Locale{LocaleEN, LocaleRU} {
+		CurrentLocale = locale
+		for _, v := range iso80000Table {
+			if s := v.Size.ISO80000(); s != v.Expected {
+				t.Fatalf("(%d).ISO80000(): expected %s, received %s", v.Size, v.Expected, s)
+			}
+		}
+	}
+}
+
+func Test_ExactBreakdown(t *testing.T) {
+	sizes := []ByteSize{0, 1, KB, GB + 200*MB + 5, 1234567890123, math.MaxUint64}
+	for i := uint64(1); i < 64; i++ {
+		sizes = append(sizes, ByteSize(i*0x9e3779b97f4a7c15>>i))
+	}
+
+	for _, size := range sizes {
+		var sum ByteSize
+		for unit, count := range size.ExactBreakdown() {
+			if count == 0 || count >= 1024 && unit != EB {
+				t.Fatalf("(%d).ExactBreakdown(): unexpected count %d for unit %d", size, count, unit)
+			}
+			sum += unit * ByteSize(count)
+		}
+		if sum != size {
+			t.Fatalf("(%d).ExactBreakdown() sums to %d", size, sum)
+		}
+	}
+}
+
+var compactExactTable = []struct {
+	Size     ByteSize
+	Expected string
+}{
+	{0, "0 B"},
+	{7919, "7919 B"},
+	{2 * MB, "2 MB"},
+	{2048 * KB, "2 MB"},
+	{3 * MB / 2, "1536 KB"},
+	{5 * EB, "5 EB"},
+	{GB + 1, "1073741825 B"},
+}
+
+func Test_CompactExact(t *testing.T) {
+	for _, v := range compactExactTable {
+		if s := v.Size.CompactExact(); s != v.Expected {
+			t.Fatalf("(%d).CompactExact(): expected %s, received %s", v.Size, v.Expected, s)
+		}
+	}
+}
+
+func Test_Sectors(t *testing.T) {
+	if n := MB.Sectors(512); n != 2048 {
+		t.Fatalf("Expected 2048 sectors, received %d", n)
+	}
+	if n := MB.Sectors(0); n != 2048 {
+		t.Fatalf("Expected the default sector size, received %d sectors", n)
+	}
+	if n := ByteSize(513).Sectors(0); n != 2 {
+		t.Fatalf("Expected partial sectors to round up, received %d", n)
+	}
+	if n := (8 * KB).Sectors(4 * KB); n != 2 {
+		t.Fatalf("Expected 2 sectors of 4 KB, received %d", n)
+	}
+
+	if b := FromSectors(2048, 0); b != MB {
+		t.Fatalf("Expected %d, received %d", MB, b)
+	}
+	if b := FromSectors(3, 4*KB); b != 12*KB {
+		t.Fatalf("Expected %d, received %d", 12*KB, b)
+	}
+	if b := FromSectors(math.MaxUint64, 0); b != math.MaxUint64 {
+		t.Fatalf("Expected saturation, received %d", b)
+	}
+}
+
+func Test_ParseInvalidUTF8(t *testing.T) {
+	for _, input := range []string{"1 \xff", "1 K\xc0B", "\xed\xa0\x80 MB", "2 МБ\xd0"} {
+		if _, err := Parse(input); err == nil || !strings.Contains(err.Error(), "invalid UTF-8") {
+			t.Fatalf("Parse(%q): expected an encoding error, received %v", input, err)
+		}
+	}
+
+	var b ByteSize
+	if err := b.UnmarshalText([]byte{'1', ' ', 'M', 0xff}); err == nil || !strings.Contains(err.Error(), "invalid UTF-8") {
+		t.Fatalf("UnmarshalText: expected an encoding error, received %v", err)
+	}
+
+	if _, err := ParseWithLocale("1 \xff", LocaleRU); err == nil || !strings.Contains(err.Error(), "кодировка") {
+		t.Fatalf("ParseWithLocale(ru): expected a localized encoding error, received %v", err)
+	}
+}
+
+var unionSizeTable = []struct {
+	Allocs   []Allocation
+	Expected ByteSize
+}{
+	{nil, 0},
+	{[]Allocation{{0, KB}}, KB},
+	{[]Allocation{{0, KB}, {2 * KB, KB}}, 2 * KB},
+	{[]Allocation{{0, 2 * KB}, {KB, 2 * KB}}, 3 * KB},
+	{[]Allocation{{KB, 2 * KB}, {0, 2 * KB}}, 3 * KB},
+	{[]Allocation{{0, 10 * KB}, {KB, KB}, {3 * KB, KB}}, 10 * KB},
+	{[]Allocation{{0, KB}, {KB, KB}}, 2 * KB},
+	{[]Allocation{{5 * KB, KB}, {0, KB}, {500, KB}, {5 * KB, 0}}, KB + 500 + KB},
+	{[]Allocation{{math.MaxUint64 - 10, 100}, {0, 10}}, 20},
+}
+
+func Test_UnionSize(t *testing.T) {
+	for _, v := range unionSizeTable {
+		if size := UnionSize(v.Allocs); size != v.Expected {
+			t.Fatalf("UnionSize(%v): expected %d, received %d", v.Allocs, v.Expected, size)
+		}
+	}
+}
+
+func Test_GroupedString(t *testing.T) {
+	originFormat := Format
+	defer func() {
+		Format = originFormat
+		SetUnitLadder(nil)
+	}()
+	Format = "%.2f "
+
+	if s := (1010 * MB).GroupedString(); s != "1,010.00 MB" {
+		t.Fatalf("Expected 1,010.00 MB, received %s", s)
+	}
+	if s := (3 * MB / 2).GroupedString(); s != "1.50 MB" {
+		t.Fatalf("Expected 1.50 MB, received %s", s)
+	}
+
+	SetUnitLadder([]ByteSize{B, MB})
+	if s := (1234*MB + 512*KB).GroupedString(); s != "1,234.50 MB" {
+		t.Fatalf("Expected 1,234.50 MB, received %s", s)
+	}
+	if s := ByteSize(1234567).GroupedString(); s != "1.18 MB" {
+		t.Fatalf("Expected 1.18 MB, received %s", s)
+	}
+}
+
+var percentOfTable = []struct {
+	Input  string
+	Total  ByteSize
+	Result ByteSize
+	Fail   bool
+}{
+	{"25%", 16 * GB, 4 * GB, false},
+	{" 12.5 % ", 16 * GB, 2 * GB, false},
+	{"0%", 16 * GB, 0, false},
+	{"100%", 16 * GB, 16 * GB, false},
+	{"150%", 2 * MB, 3 * MB, false},
+	{"33%", 1, 0, false},
+	{"50%", 3, 2, false},
+	{"25", 16 * GB, 0, true},
+	{"%", 16 * GB, 0, true},
+	{"-5%", 16 * GB, 0, true},
+	{"NaN%", 16 * GB, 0, true},
+	{"ten%", 16 * GB, 0, true},
+	{"200%", 10 * EB, 0, true},
+}
+
+func Test_ParsePercentOf(t *testing.T) {
+	for _, v := range percentOfTable {
+		b, err := ParsePercentOf(v.Input, v.Total)
+		if v.Fail {
+			if err == nil {
+				t.Fatalf("ParsePercentOf(%q, %d): expected error, received %d", v.Input, v.Total, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("ParsePercentOf(%q, %d): expected %d, received %d", v.Input, v.Total, v.Result, b)
+		}
+	}
+}
+
+var memoryPercentTable = []struct {
+	Input  string
+	Result ByteSize
+	Fail   bool
+}{
+	{"25%", 4 * GB, false},
+	{"100 %", 16 * GB, false},
+	{"512 MB", 512 * MB, false},
+	{"32 GB", 32 * GB, false},
+	{"101%", 0, true},
+	{"lots", 0, true},
+}
+
+func Test_ParseMemoryPercent(t *testing.T) {
+	for _, v := range memoryPercentTable {
+		b, err := ParseMemoryPercent(v.Input, 16*GB)
+		if v.Fail {
+			if err == nil {
+				t.Fatalf("ParseMemoryPercent(%q): expected error, received %s", v.Input, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("ParseMemoryPercent(%q): expected %s, received %s", v.Input, v.Result, b)
+		}
+	}
+}
+
+var checkedMathTable = []struct {
+	Op     string
+	A      ByteSize
+	B      uint64
+	Result ByteSize
+	Err    error
+}{
+	{"add", GB, uint64(MB), GB + MB, nil},
+	{"add", math.MaxUint64 - 1, 1, math.MaxUint64, nil},
+	{"add", math.MaxUint64, 1, 0, ErrOverflow},
+	{"add", 15 * EB, uint64(EB), 0, ErrOverflow},
+	{"sub", GB, uint64(MB), GB - MB, nil},
+	{"sub", MB, uint64(MB), 0, nil},
+	{"sub", 0, 1, 0, ErrUnderflow},
+	{"sub", MB, uint64(MB) + 1, 0, ErrUnderflow},
+	{"mul", 4 * EB, 3, 12 * EB, nil},
+	{"mul", EB, 16, 0, ErrOverflow},
+	{"mul", math.MaxUint64, 1, math.MaxUint64, nil},
+	{"mul", math.MaxUint64, 0, 0, nil},
+	{"mul", 0, math.MaxUint64, 0, nil},
+	{"mul", 1 << 32, 1 << 32, 0, ErrOverflow},
+}
+
+func Test_CheckedMath(t *testing.T) {
+	for _, v := range checkedMathTable {
+		var b ByteSize
+		var err error
+		switch v.Op {
+		case "add":
+			b, err = v.A.Add(ByteSize(v.B))
+		case "sub":
+			b, err = v.A.Sub(ByteSize(v.B))
+		case "mul":
+			b, err = v.A.Mul(v.B)
+		}
+		if !errors.Is(err, v.Err) {
+			t.Fatalf("%s(%d, %d): expected error %v, received %v", v.Op, v.A, v.B, v.Err, err)
+		}
+		if err == nil && b != v.Result {
+			t.Fatalf("%s(%d, %d): expected %d, received %d", v.Op, v.A, v.B, v.Result, b)
+		}
+	}
+}
+
+// dfStyleTable holds sizes with the output of "df -h" and "df -H" for them.
+var dfStyleTable = []struct {
+	Input   ByteSize
+	Binary  string
+	Decimal string
+}{
+	{0, "0", "0"},
+	{512, "512", "512"},
+	{999, "999", "999"},
+	{1000, "1000", "1.0k"},
+	{KB, "1.0K", "1.1k"},
+	{4096, "4.0K", "4.1k"},
+	{10 * KB, "10K", "11k"},
+	{1023 * KB, "1023K", "1.1M"},
+	{MB - 1, "1.0M", "1.1M"},
+	{9*GB + 800*MB, "9.8G", "11G"},
+	{23 * GB, "23G", "25G"},
+	{10*GB - 1, "10G", "11G"},
+	{234 * GB, "234G", "252G"},
+	{1023*GB + 1, "1.0T", "1.1T"},
+	{2 * TB, "2.0T", "2.2T"},
+	{EB, "1.0E", "1.2E"},
+	{math.MaxUint64, "16E", "19E"},
+}
+
+func Test_DfStyle(t *testing.T) {
+	for _, v := range dfStyleTable {
+		if got := v.Input.DfStyle(false); got != v.Binary {
+			t.Fatalf("DfStyle(%d, binary): expected %q, received %q", uint64(v.Input), v.Binary, got)
+		}
+		if got := v.Input.DfStyle(true); got != v.Decimal {
+			t.Fatalf("DfStyle(%d, decimal): expected %q, received %q", uint64(v.Input), v.Decimal, got)
+		}
+	}
+}
+
+func Test_CompareMethods(t *testing.T) {
+	sizes := []ByteSize{GB, 1, math.MaxUint64, MB, 0, KB, MB, 512 * KB}
+	slices.SortFunc(sizes, ByteSize.Compare)
+	expected := []ByteSize{0, 1, KB, 512 * KB, MB, MB, GB, math.MaxUint64}
+	if !slices.Equal(sizes, expected) {
+		t.Fatalf("Expected %v, received %v", expected, sizes)
+	}
+
+	if c := MB.Compare(KB); c != 1 {
+		t.Fatalf("MB.Compare(KB): expected 1, received %d", c)
+	}
+	if c := KB.Compare(MB); c != -1 {
+		t.Fatalf("KB.Compare(MB): expected -1, received %d", c)
+	}
+	if c := MB.Compare(1024 * KB); c != 0 {
+		t.Fatalf("MB.Compare(1024 * KB): expected 0, received %d", c)
+	}
+	if !KB.Less(MB) || MB.Less(KB) || KB.Less(KB) {
+		t.Fatal("Less does not match <")
+	}
+	if !MB.Equal(1024*KB) || MB.Equal(KB) {
+		t.Fatal("Equal does not match ==")
+	}
+	if !MB.GreaterThan(KB) || KB.GreaterThan(MB) || KB.GreaterThan(KB) {
+		t.Fatal("GreaterThan does not match >")
+	}
+}
+
+var unitAccessorTable = []struct {
+	Name   string
+	Method func(ByteSize) float64
+	Input  ByteSize
+	Result float64
+}{
+	{"Kilobytes", ByteSize.Kilobytes, 1536, 1.5},
+	{"Megabytes", ByteSize.Megabytes, 2 * MB, 2.0},
+	{"Megabytes", ByteSize.Megabytes, 512 * KB, 0.5},
+	{"Gigabytes", ByteSize.Gigabytes, 3 * GB, 3.0},
+	{"Terabytes", ByteSize.Terabytes, 5 * TB / 4, 1.25},
+	{"Petabytes", ByteSize.Petabytes, 7 * PB, 7.0},
+	{"Exabytes", ByteSize.Exabytes, 15 * EB, 15.0},
+	{"Exabytes", ByteSize.Exabytes, 0, 0},
+}
+
+func Test_UnitAccessors(t *testing.T) {
+	for _, v := range unitAccessorTable {
+		if got := v.Method(v.Input); got != v.Result {
+			t.Fatalf("%s(%d): expected %v, received %v", v.Name, uint64(v.Input), v.Result, got)
+		}
+	}
+	if got := (3 * KB).Bytes(); got != 3072 {
+		t.Fatalf("Bytes(3 KB): expected 3072, received %d", got)
+	}
+}
+
+var humanReadableTable = []struct {
+	Input  ByteSize
+	Result string
+}{
+	{0, "0 B"},
+	{1023, "1023 B"},
+	{1024, "1 KB"},
+	{1536, "1.5 KB"},
+	{1500000, "1.43 MB"},
+	{10 * GB, "10 GB"},
+}
+
+func Test_HumanReadable(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	originLocale := CurrentLocale
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+		CurrentLocale = originLocale
+	}()
+	Format = "%.0f"
+	LongUnits = false
+	CurrentLocale = LocaleEN
+
+	for _, v := range humanReadableTable {
+		if got := v.Input.HumanReadable(); got != v.Result {
+			t.Fatalf("HumanReadable(%d): expected %q, received %q", uint64(v.Input), v.Result, got)
+		}
+	}
+
+	SetLocale(LocaleRU)
+	if got := ByteSize(1536).HumanReadable(); got != "1.5 КБ" {
+		t.Fatalf("HumanReadable(1536) in Russian: expected %q, received %q", "1.5 КБ", got)
+	}
+}
+
+var fitWidthTable = []struct {
+	Input    ByteSize
+	MaxChars int
+	Result   string
+}{
+	{1536, 10, "1.50 KB"},
+	{1536, 7, "1.50 KB"},
+	{1536, 6, "1.5 KB"},
+	{1536, 4, "2 KB"},
+	{1536, 3, "2KB"},
+	{1023 * KB, 6, "1.0 MB"},
+	{1023 * KB, 4, "1 MB"},
+	{1023, 5, "1 KB"},
+	{0, 3, "0 B"},
+	{1, 2, "1B"},
+	{math.MaxUint64, 5, "16 EB"},
+	{math.MaxUint64, 3, "16E"},
+	{GB, 0, ""},
+}
+
+func Test_FitWidth(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	originLocale := CurrentLocale
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+		CurrentLocale = originLocale
+	}()
+	Format = "%.2f "
+	LongUnits = false
+	CurrentLocale = LocaleEN
+
+	for _, v := range fitWidthTable {
+		if got := v.Input.FitWidth(v.MaxChars); got != v.Result {
+			t.Fatalf("FitWidth(%d, %d): expected %q, received %q", uint64(v.Input), v.MaxChars, v.Result, got)
+		}
+	}
+
+	for _, locale := range []Locale{LocaleEN, LocaleRU, LocaleFR} {
+		SetLocale(locale)
+		for _, longUnits := range []bool{false, true} {
+			LongUnits = longUnits
+			for size := ByteSize(1); size < math.MaxUint64/7; size = size*7 + 3 {
+				for width := 1; width <= 12; width++ {
+					if got := size.FitWidth(width); utf8.RuneCountInString(got) > width {
+						t.Fatalf("FitWidth(%d, %d) = %q exceeds the budget", uint64(size), width, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+var groupingTable = []struct {
+	Locale Locale
+	Input  string
+	Result ByteSize
+	Fail   bool
+}{
+	{LocaleEN, "1 024 KB", MB, false},
+	{LocaleEN, "1 048 576 B", MB, false},
+	{LocaleEN, "1,048,576 B", MB, false},
+	{LocaleEN, "1,000 B", 1000, false},
+	{LocaleEN, "1,000.5 KB", 1000*KB + 512, false},
+	{LocaleEN, "1.048.576 B", MB, false},
+	{LocaleEN, "1.000,5 KB", 1000*KB + 512, false},
+	{LocaleEN, "1.5 MB", 3 * MB / 2, false},
+	{LocaleEN, "1,5 MB", 0, true},
+	{LocaleEN, "12 34 KB", 0, true},
+	{LocaleEN, "1,000,5 KB", 0, true},
+	{LocaleRU, "1 024 КБ", MB, false},
+	{LocaleRU, "1,5 MB", 3 * MB / 2, false},
+	{LocaleRU, "1,000 Б", 1, false},
+	{LocaleRU, "1,048,576 B", MB, false},
+	{LocaleRU, "1,000.5 KB", 1000*KB + 512, false},
+	{LocaleRU, "1.048.576 B", MB, false},
+	{LocaleDE, "1,5 MB", 3 * MB / 2, false},
+	{LocaleDE, "1.024 KB", MB, false},
+	{LocaleDE, "1,048,576 B", MB, false},
+	{LocaleFR, "1 048 576 o", MB, false},
+	{LocaleFR, "1,5 Mo", 3 * MB / 2, false},
+}
+
+func Test_ParseGrouping(t *testing.T) {
+	for _, v := range groupingTable {
+		b, err := ParseWithLocale(v.Input, v.Locale)
+		if v.Fail {
+			if err == nil {
+				t.Fatalf("ParseWithLocale(%q, %s): expected error, received %d", v.Input, v.Locale, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseWithLocale(%q, %s): %v", v.Input, v.Locale, err)
+		}
+		if b != v.Result {
+			t.Fatalf("ParseWithLocale(%q, %s): expected %d, received %d", v.Input, v.Locale, v.Result, b)
+		}
+	}
+}
+
+var scientificTable = []struct {
+	Input  string
+	Result ByteSize
+	Fail   bool
+}{
+	{"1e3 KB", 1000 * KB, false},
+	{"1.5e2 MB", 150 * MB, false},
+	{"2E1 GB", 20 * GB, false},
+	{"1e+3 KB", 1000 * KB, false},
+	{"5e-1 KB", 512, false},
+	{"1e3KB", 1000 * KB, false},
+	{"2e2 exabytes", 0, true},
+	{"1e KB", 0, true},
+	{"1e3e2 KB", 0, true},
+}
+
+func Test_ParseScientific(t *testing.T) {
+	for _, v := range scientificTable {
+		b, err := Parse(v.Input)
+		if v.Fail {
+			if err == nil {
+				t.Fatalf("Parse(%q): expected error, received %d", v.Input, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("Parse(%q): expected %d, received %d", v.Input, v.Result, b)
+		}
+	}
+}
+
+var signTable = []struct {
+	Input  string
+	Result ByteSize
+	Fail   bool
+}{
+	{"+1 MB", MB, false},
+	{"+1.5KB", 1536, false},
+	{"+0 B", 0, false},
+	{"-1 MB", 0, true},
+	{"-0 B", 0, true},
+	{"++1 MB", 0, true},
+	{"+-1 MB", 0, true},
+	{"+ MB", 0, true},
+}
+
+func Test_ParseSign(t *testing.T) {
+	for _, v := range signTable {
+		b, err := Parse(v.Input)
+		if v.Fail {
+			if err == nil {
+				t.Fatalf("Parse(%q): expected error, received %d", v.Input, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("Parse(%q): expected %d, received %d", v.Input, v.Result, b)
+		}
+	}
+}
+
+var parseUnitTable = []struct {
+	Input  string
+	Result ByteSize
+	Fail   bool
+}{
+	{"MB", MB, false},
+	{" KB ", KB, false},
+	{"kilobyte", KB, false},
+	{"GiB", GB, false},
+	{"B", 1, false},
+	{"3 MB", 3 * MB, false},
+	{"1.5KB", 1536, false},
+	{"XB", 0, true},
+	{"", 0, true},
+	{"12", 0, true},
+}
+
+func Test_ParseUnit(t *testing.T) {
+	for _, v := range parseUnitTable {
+		b, err := ParseUnit(v.Input)
+		if v.Fail {
+			if err == nil {
+				t.Fatalf("ParseUnit(%q): expected error, received %d", v.Input, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != v.Result {
+			t.Fatalf("ParseUnit(%q): expected %d, received %d", v.Input, v.Result, b)
+		}
+	}
+
+	if _, err := Parse("MB"); err == nil {
+		t.Fatal("Parse(\"MB\"): expected error")
+	}
+}
+
+func Test_MustParse(t *testing.T) {
+	if b := MustParse("1.5 GB"); b != 3*GB/2 {
+		t.Fatalf("MustParse(\"1.5 GB\"): expected %d, received %d", 3*GB/2, b)
+	}
+	if b := MustParseLocale("2 КБ", LocaleRU); b != 2*KB {
+		t.Fatalf("MustParseLocale(\"2 КБ\"): expected %d, received %d", 2*KB, b)
+	}
+
+	mustPanic := func(name string, want string, f func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatalf("%s: expected a panic", name)
+			}
+			if msg, _ := r.(string); !strings.HasPrefix(msg, want) {
+				t.Fatalf("%s: panic %q does not start with %q", name, r, want)
+			}
+		}()
+		f()
+	}
+	mustPanic("MustParse", `bytesize: Parse("lots"): `, func() { MustParse("lots") })
+	mustPanic("MustParseLocale", `bytesize: ParseWithLocale("1 MB", xx): `, func() { MustParseLocale("1 MB", "xx") })
+}
+
+var inTable = []struct {
+	Input  ByteSize
+	Unit   ByteSize
+	Result string
+}{
+	{512 * KB, MB, "0.50MB"},
+	{512 * KB, KB, "512.00KB"},
+	{512 * KB, B, "524288.00B"},
+	{3 * GB / 2, MB, "1536.00MB"},
+	{3 * GB / 2, TB, "0.00TB"},
+	{PB, EB, "0.00EB"},
+	{2 * EB, PB, "2048.00PB"},
+	{KB, 1000, "1024.00B"},
+	{KB, 0, "1024.00B"},
+}
+
+func Test_In(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	originLocale := CurrentLocale
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+		CurrentLocale = originLocale
+	}()
+	Format = "%.2f"
+	LongUnits = false
+	CurrentLocale = LocaleEN
+
+	for _, v := range inTable {
+		if got := v.Input.In(v.Unit); got != v.Result {
+			t.Fatalf("In(%d, %d): expected %q, received %q", uint64(v.Input), uint64(v.Unit), v.Result, got)
+		}
+	}
+
+	SetLocale(LocaleRU)
+	Format = "%.1f "
+	if got := (512 * KB).In(MB); got != "0.5 МБ" {
+		t.Fatalf("In in Russian: received %q", got)
+	}
+}
+
+var roundTable = []struct {
+	Input ByteSize
+	Unit  ByteSize
+	Round ByteSize
+	Up    ByteSize
+	Down  ByteSize
+}{
+	{1536 * KB, MB, 2 * MB, 2 * MB, MB},
+	{1535 * KB, MB, MB, 2 * MB, MB},
+	{1537 * KB, MB, 2 * MB, 2 * MB, MB},
+	{2 * MB, MB, 2 * MB, 2 * MB, 2 * MB},
+	{0, GB, 0, 0, 0},
+	{5, 2, 6, 6, 4},
+	{4, 3, 3, 6, 3},
+	{5, 3, 6, 6, 3},
+	{1, 1, 1, 1, 1},
+	{7, 0, 7, 7, 7},
+	{math.MaxUint64, EB, 15 * EB, 15 * EB, 15 * EB},
+	{math.MaxUint64, B, math.MaxUint64, math.MaxUint64, math.MaxUint64},
+}
+
+func Test_Round(t *testing.T) {
+	for _, v := range roundTable {
+		if got := v.Input.Round(v.Unit); got != v.Round {
+			t.Fatalf("Round(%d, %d): expected %d, received %d", v.Input, v.Unit, v.Round, got)
+		}
+		if got := v.Input.RoundUp(v.Unit); got != v.Up {
+			t.Fatalf("RoundUp(%d, %d): expected %d, received %d", v.Input, v.Unit, v.Up, got)
+		}
+		if got := v.Input.RoundDown(v.Unit); got != v.Down {
+			t.Fatalf("RoundDown(%d, %d): expected %d, received %d", v.Input, v.Unit, v.Down, got)
+		}
+	}
+}
+
+var trimZerosTable = []struct {
+	Input     ByteSize
+	LongUnits bool
+	Result    string
+}{
+	{KB, false, "1KB"},
+	{3 * MB / 2, false, "1.5MB"},
+	{5 * GB / 4, false, "1.25GB"},
+	{1023, false, "1023B"},
+	{5 * MB / 4, false, "1.25MB"},
+	{KB, true, "1kilobyte"},
+	{3 * MB / 2, true, "1.5megabytes"},
+	{5 * GB / 4, true, "1.25gigabytes"},
+}
+
+func Test_TrimZeros(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	originLocale := CurrentLocale
+	originTrimZeros := TrimZeros
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+		CurrentLocale = originLocale
+		TrimZeros = originTrimZeros
+	}()
+	Format = "%.2f"
+	CurrentLocale = LocaleEN
+	TrimZeros = true
+
+	for _, v := range trimZerosTable {
+		LongUnits = v.LongUnits
+		if got := v.Input.String(); got != v.Result {
+			t.Fatalf("String(%d) with TrimZeros: expected %q, received %q", uint64(v.Input), v.Result, got)
+		}
+	}
+
+	Format = "%.2f "
+	for _, locale := range []Locale{LocaleEN, LocaleRU, LocaleHI, LocaleDE, LocaleFR, LocalePL} {
+		SetLocale(locale)
+		for _, longUnits := range []bool{false, true} {
+			LongUnits = longUnits
+			for _, size := range []ByteSize{KB, 3 * MB / 2, 5 * GB / 4} {
+				got := size.String()
+				if strings.Contains(got, "0 ") || strings.Contains(got, ". ") {
+					t.Fatalf("String(%d) in %s = %q, expected trailing zeros to be trimmed", uint64(size), locale, got)
+				}
+				if parsed, err := Parse(got); err != nil || parsed != size {
+					t.Fatalf("Parse(%q) in %s = %d, %v, expected %d", got, locale, parsed, err, size)
+				}
+			}
+		}
+	}
+
+	TrimZeros = false
+	SetLocale(LocaleEN)
+	LongUnits = false
+	if got := KB.String(); got != "1.00 KB" {
+		t.Fatalf("String(KB) without TrimZeros: expected %q, received %q", "1.00 KB", got)
+	}
+}
+
+var fromFloat64Table = []struct {
+	Input  float64
+	Result ByteSize
+}{
+	{0, 0},
+	{1.4, 1},
+	{1.5, 2},
+	{1536, 1536},
+	{float64(15 * EB), 15 * EB},
+	{float64(EB) + 4096, EB + 4096},
+	{1e30, math.MaxUint64},
+	{math.Inf(1), math.MaxUint64},
+	{-1, 0},
+	{-1e30, 0},
+	{math.Inf(-1), 0},
+	{math.NaN(), 0},
+}
+
+func Test_Float64(t *testing.T) {
+	for _, v := range fromFloat64Table {
+		if got := FromFloat64(v.Input); got != v.Result {
+			t.Fatalf("FromFloat64(%v): expected %d, received %d", v.Input, v.Result, got)
+		}
+	}
+
+	for _, size := range []ByteSize{0, 1, 3 * MB / 2, 15 * EB} {
+		if got := size.Float64(); got != float64(size) {
+			t.Fatalf("Float64(%d): expected %v, received %v", uint64(size), float64(size), got)
+		}
+		if got := FromFloat64(size.Float64()); got != size {
+			t.Fatalf("FromFloat64(Float64(%d)) = %d", uint64(size), got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package bytesize
+
+import "testing"
+
+func TestDeLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ByteSize
+	}{
+		{"Kurzform", "2 KB", 2 * KB},
+		{"Kilobyte Einzahl", "1 Kilobyte", KB},
+		{"Kilobyte Mehrzahl", "5 Kilobytes", 5 * KB},
+		{"Kilobyte ohne s", "5 Kilobyte", 5 * KB},
+		{"Byte", "512 Byte", 512},
+		{"Bytes", "512 Bytes", 512},
+		{"Kleinschreibung", "3 gigabyte", 3 * GB},
+		{"Tausenderpunkt", "1.024 KB", 1024 * KB},
+		{"Dezimalpunkt", "1.5 MB", 3 * MB / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseWithLocale(tt.input, LocaleDE)
+			if err != nil {
+				t.Errorf("ParseWithLocale(%q) error = %v", tt.input, err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("ParseWithLocale(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGermanFormatting(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	SetLocale(LocaleDE)
+	Format = "%.0f "
+
+	tests := []struct {
+		name      string
+		size      ByteSize
+		longUnits bool
+		expected  string
+	}{
+		{"0 Bytes", New(0), true, "0 Bytes"},
+		{"1 Byte", New(1), true, "1 Byte"},
+		{"5 Bytes", New(5), true, "5 Bytes"},
+		{"1 Kilobyte", KB, true, "1 Kilobyte"},
+		{"5 Kilobytes", 5 * KB, true, "5 Kilobytes"},
+		{"2 Gigabytes", 2 * GB, true, "2 Gigabytes"},
+		{"Gerundet 1 Kilobyte", KB + 1, true, "1 Kilobyte"},
+		{"Gerundet 2 Kilobytes", 2*KB - 1, true, "2 Kilobytes"},
+		{"Kurzform", 2 * GB, false, "2 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			LongUnits = tt.longUnits
+			if result := tt.size.String(); result != tt.expected {
+				t.Errorf("Size %d String() = %q, expected %q", tt.size, result, tt.expected)
+			}
+		})
+	}
+
+	// Dezimalkomma, damit der Punkt nicht als Tausendertrennzeichen gilt.
+	if result := (3 * MB / 2).Format("%.3f ", "MB", true); result != "1,500 Megabytes" {
+		t.Errorf("Format() = %q, expected %q", result, "1,500 Megabytes")
+	}
+	if result, err := Parse((3 * MB / 2).Format("%.3f ", "MB", true)); err != nil || result != 3*MB/2 {
+		t.Errorf("Parse(Format()) = %d, %v, expected %d", result, err, 3*MB/2)
+	}
+
+	if result := (1234567 * B).GroupedBytes(); result != "1.234.567 B" {
+		t.Errorf("GroupedBytes() = %q, expected %q", result, "1.234.567 B")
+	}
+	if result := (GB + 200*MB).BreakdownWords(LocaleDE, 0); result != "1 Gigabyte und 200 Megabytes" {
+		t.Errorf("BreakdownWords() = %q", result)
+	}
+}
+
+func TestGermanCommaDecimal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+	}{
+		{"1,5 MB", 3 * MB / 2},
+		{"1.000,5 KB", 1000*KB + 512},
+		{"1.024 B", 1024},
+		{"1.5 MB", 3 * MB / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseWithLocale(tt.input, LocaleDE)
+			if err != nil {
+				t.Fatalf("ParseWithLocale(%q) error = %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseWithLocale(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
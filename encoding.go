@@ -0,0 +1,193 @@
+package bytesize
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Milli returns b in thousandths of a byte. Sizes larger than
+// math.MaxUint64/1000 bytes saturate at math.MaxUint64.
+func (b ByteSize) Milli() uint64 {
+	if b > math.MaxUint64/1000 {
+		return math.MaxUint64
+	}
+	return uint64(b) * 1000
+}
+
+// FromMilli returns the ByteSize for m thousandths of a byte, rounded to the
+// nearest byte.
+func FromMilli(m uint64) ByteSize {
+	b := ByteSize(m / 1000)
+	if m%1000 >= 500 {
+		b++
+	}
+	return b
+}
+
+// EncodeDeltas encodes sizes compactly as the varint-encoded differences
+// between consecutive byte counts, the first taken relative to zero. Slowly
+// changing series, such as a growing log file, take a byte or two per entry.
+func EncodeDeltas(sizes []ByteSize) []byte {
+	buf := make([]byte, 0, len(sizes)*2)
+	var prev ByteSize
+	for _, b := range sizes {
+		buf = binary.AppendVarint(buf, int64(b-prev))
+		prev = b
+	}
+	return buf
+}
+
+// DecodeDeltas decodes data produced by EncodeDeltas. It returns an error if
+// data is truncated or malformed.
+func DecodeDeltas(data []byte) ([]ByteSize, error) {
+	var sizes []ByteSize
+	var prev ByteSize
+	for len(data) > 0 {
+		delta, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, errors.New("invalid delta encoding")
+		}
+		prev += ByteSize(delta)
+		sizes = append(sizes, prev)
+		data = data[n:]
+	}
+	return sizes, nil
+}
+
+// MarshalJSON returns b as a JSON string holding the MarshalText form, e.g.
+// "2.00 MB". It implements the json.Marshaler interface.
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	text, err := b.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON sets b from a JSON string such as "2 MB", parsed like
+// UnmarshalText, or from a bare JSON number taken as a raw byte count. A
+// JSON null leaves b unchanged. It implements the json.Unmarshaler
+// interface.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case string(data) == "null":
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return b.UnmarshalText([]byte(s))
+	}
+
+	n, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return errors.New("byte size must be a string or a non-negative integer: " + string(data))
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+// MarshalYAML returns the MarshalText form of b as a string, e.g. "2.00 MB".
+// It implements the yaml.Marshaler interface of gopkg.in/yaml.v2 and v3.
+func (b ByteSize) MarshalYAML() (interface{}, error) {
+	text, err := b.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// UnmarshalYAML sets b from a YAML scalar: a plain integer is taken as a raw
+// byte count and anything else is parsed like UnmarshalText. It implements
+// the obsolete yaml.Unmarshaler interface, which gopkg.in/yaml.v3 still
+// honours.
+func (b *ByteSize) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var n uint64
+	if err := unmarshal(&n); err == nil {
+		*b = ByteSize(n)
+		return nil
+	}
+
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return b.UnmarshalText([]byte(s))
+}
+
+// OrderedBytes returns b as 8 big-endian bytes, so that comparing the
+// encodings of two sizes byte by byte orders them like the sizes. This
+// suits keys for range scans in ordered key-value stores.
+func (b ByteSize) OrderedBytes() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(b))
+}
+
+// FromOrderedBytes decodes a size encoded by OrderedBytes. It returns an
+// error if data is not exactly 8 bytes long.
+func FromOrderedBytes(data []byte) (ByteSize, error) {
+	if len(data) != 8 {
+		return 0, errors.New("ordered encoding must be 8 bytes long")
+	}
+	return ByteSize(binary.BigEndian.Uint64(data)), nil
+}
+
+// DecodeHook returns a decode hook for github.com/mitchellh/mapstructure,
+// and therefore viper, that decodes config values into ByteSize. Strings
+// are parsed like UnmarshalText and whole numbers are taken as raw byte
+// counts. Strings are also decoded into the package's other types that
+// implement encoding.TextUnmarshaler. Values for other types pass through
+// unchanged.
+//
+// The returned function has the signature of mapstructure.DecodeHookFuncType,
+// so this package does not depend on mapstructure.
+func DecodeHook() func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if to.PkgPath() != byteSizeType.PkgPath() {
+			return data, nil
+		}
+
+		if to == byteSizeType {
+			v := reflect.ValueOf(data)
+			switch v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				if v.Int() < 0 {
+					return nil, fmt.Errorf("negative byte size %d", v.Int())
+				}
+				return ByteSize(v.Int()), nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				return ByteSize(v.Uint()), nil
+			case reflect.Float32, reflect.Float64:
+				f := v.Float()
+				if f < 0 || f >= math.MaxUint64 || f != math.Trunc(f) {
+					return nil, fmt.Errorf("byte size %v is not a whole number of bytes", f)
+				}
+				return ByteSize(f), nil
+			}
+		}
+
+		s, ok := data.(string)
+		if !ok {
+			return data, nil
+		}
+		target, ok := reflect.New(to).Interface().(encoding.TextUnmarshaler)
+		if !ok {
+			return data, nil
+		}
+		if err := target.UnmarshalText([]byte(s)); err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(target).Elem().Interface(), nil
+	}
+}
+
+// byteSizeType is the reflect.Type of ByteSize.
+var byteSizeType = reflect.TypeOf(ByteSize(0))
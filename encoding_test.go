@@ -0,0 +1,362 @@
+package bytesize
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMilli(t *testing.T) {
+	for _, b := range []ByteSize{0, 1, 1023, KB, 3 * MB / 2, 7 * GB, 16 * PB, math.MaxUint64 / 1000} {
+		if m := b.Milli(); m != uint64(b)*1000 {
+			t.Errorf("(%d).Milli() = %d, expected %d", b, m, uint64(b)*1000)
+		}
+		if r := FromMilli(b.Milli()); r != b {
+			t.Errorf("FromMilli((%d).Milli()) = %d", b, r)
+		}
+	}
+
+	if m := (math.MaxUint64/1000 + 1) * B; m.Milli() != math.MaxUint64 {
+		t.Errorf("Milli() did not saturate on overflow")
+	}
+
+	tests := []struct {
+		milli    uint64
+		expected ByteSize
+	}{
+		{0, 0},
+		{499, 0},
+		{500, 1},
+		{1499, 1},
+		{1500, 2},
+		{math.MaxUint64, math.MaxUint64/1000 + 1},
+	}
+	for _, tt := range tests {
+		if b := FromMilli(tt.milli); b != tt.expected {
+			t.Errorf("FromMilli(%d) = %d, expected %d", tt.milli, b, tt.expected)
+		}
+	}
+}
+
+func TestMarshalTextRoundTrip(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	rng := rand.New(rand.NewSource(1))
+	sizes := []ByteSize{0, 1, 1023, KB, 1234, MB, 3 * MB / 2, EB, math.MaxUint64, math.MaxUint64 - 1}
+	for i := 0; i < 2000; i++ {
+		// Spread the values over every magnitude, not just the top one.
+		sizes = append(sizes, ByteSize(rng.Uint64()>>rng.Intn(64)))
+	}
+
+	for _, locale := range []Locale{LocaleEN, LocaleRU, LocaleHI} {
+		for _, format := range []string{"%.2f ", "%.0f", "%x"} {
+			for _, longUnits := range []bool{false, true} {
+				CurrentLocale, Format, LongUnits = locale, format, longUnits
+				for _, b := range sizes {
+					text, err := b.MarshalText()
+					if err != nil {
+						t.Fatalf("(%d).MarshalText() error = %v", uint64(b), err)
+					}
+					var got ByteSize
+					if err := got.UnmarshalText(text); err != nil {
+						t.Fatalf("UnmarshalText(%q) error = %v", text, err)
+					}
+					if got != b {
+						t.Fatalf("UnmarshalText(%q) = %d, expected %d", text, uint64(got), uint64(b))
+					}
+				}
+			}
+		}
+	}
+
+	Format, LongUnits, CurrentLocale = "%.2f ", false, LocaleEN
+	for b, expected := range map[ByteSize]string{2 * MB: "2.00 MB", 1234: "1234 B", 0: "0.00 B"} {
+		if text, _ := b.MarshalText(); string(text) != expected {
+			t.Errorf("(%d).MarshalText() = %q, expected %q", uint64(b), text, expected)
+		}
+	}
+}
+
+func TestDeltas(t *testing.T) {
+	series := [][]ByteSize{
+		nil,
+		{0},
+		{KB, 2 * KB, 2*KB + 17, 8 * MB},
+		{math.MaxUint64, 0, math.MaxUint64, 1},
+		{5 * GB, 3 * GB, GB},
+	}
+	for _, sizes := range series {
+		decoded, err := DecodeDeltas(EncodeDeltas(sizes))
+		if err != nil {
+			t.Fatalf("DecodeDeltas(EncodeDeltas(%v)): %v", sizes, err)
+		}
+		if len(decoded) != len(sizes) {
+			t.Fatalf("DecodeDeltas(EncodeDeltas(%v)) = %v", sizes, decoded)
+		}
+		for i := range sizes {
+			if decoded[i] != sizes[i] {
+				t.Fatalf("DecodeDeltas(EncodeDeltas(%v)) = %v", sizes, decoded)
+			}
+		}
+	}
+
+	// A slowly growing series should take far less than 8 bytes per entry.
+	growing := make([]ByteSize, 1000)
+	size := 10 * GB
+	for i := range growing {
+		size += ByteSize(rand.Intn(4096))
+		growing[i] = size
+	}
+	if encoded := EncodeDeltas(growing); len(encoded) > 3*len(growing) {
+		t.Errorf("EncodeDeltas of %d sizes took %d bytes", len(growing), len(encoded))
+	}
+
+	if _, err := DecodeDeltas([]byte{0x80}); err == nil {
+		t.Error("DecodeDeltas accepted a truncated varint")
+	}
+}
+
+func TestMarshalTextJSON(t *testing.T) {
+	originalFormat := Format
+	defer func() { Format = originalFormat }()
+	Format = "%.2f "
+
+	type config struct {
+		Limit ByteSize
+	}
+
+	data, err := json.Marshal(config{Limit: 2 * MB})
+	if err != nil {
+		t.Fatalf("json.Marshal error = %v", err)
+	}
+	if string(data) != `{"Limit":"2.00 MB"}` {
+		t.Errorf("json.Marshal = %s, expected the String form", data)
+	}
+
+	var decoded config
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", data, err)
+	}
+	if decoded.Limit != 2*MB {
+		t.Errorf("json round trip = %d, expected %d", decoded.Limit, 2*MB)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	originalFormat := Format
+	defer func() { Format = originalFormat }()
+	Format = "%.2f"
+
+	if data, err := json.Marshal(2 * MB); err != nil || string(data) != `"2.00MB"` {
+		t.Errorf("json.Marshal(2 MB) = %s, %v", data, err)
+	}
+
+	tests := []struct {
+		input    string
+		expected ByteSize
+	}{
+		{`"2 MB"`, 2 * MB},
+		{`"1.5GB"`, 3 * GB / 2},
+		{`2097152`, 2 * MB},
+		{` 0 `, 0},
+	}
+	for _, tt := range tests {
+		var b ByteSize
+		if err := json.Unmarshal([]byte(tt.input), &b); err != nil || b != tt.expected {
+			t.Errorf("json.Unmarshal(%s) = %d, %v, expected %d", tt.input, b, err, tt.expected)
+		}
+	}
+
+	for _, input := range []string{`"2 XB"`, `-1`, `1.5`, `true`, `{}`} {
+		var b ByteSize
+		if err := json.Unmarshal([]byte(input), &b); err == nil {
+			t.Errorf("json.Unmarshal(%s): expected error", input)
+		}
+	}
+
+	type limits struct {
+		Upload ByteSize  `json:"upload"`
+		Cache  *ByteSize `json:"cache"`
+	}
+	cache := 512 * KB
+	data, err := json.Marshal(limits{Upload: 50 * MB, Cache: &cache})
+	if err != nil {
+		t.Fatalf("json.Marshal error = %v", err)
+	}
+	var decoded limits
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", data, err)
+	}
+	if decoded.Upload != 50*MB || decoded.Cache == nil || *decoded.Cache != cache {
+		t.Errorf("struct round trip of %s = %+v", data, decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"upload": 1024, "cache": null}`), &decoded); err != nil || decoded.Upload != KB {
+		t.Errorf("json.Unmarshal with a numeric field = %+v, %v", decoded, err)
+	}
+}
+
+// yamlScalar returns an unmarshal function like the one a YAML decoder
+// passes to UnmarshalYAML for a scalar node with the given source text.
+func yamlScalar(value string) func(interface{}) error {
+	return func(v interface{}) error {
+		quoted := len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]
+		switch v := v.(type) {
+		case *uint64:
+			if quoted {
+				return errors.New("cannot unmarshal !!str into uint64")
+			}
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return errors.New("cannot unmarshal !!str into uint64")
+			}
+			*v = n
+		case *string:
+			if quoted {
+				value = value[1 : len(value)-1]
+			}
+			*v = value
+		default:
+			return errors.New("unsupported target")
+		}
+		return nil
+	}
+}
+
+func TestYAML(t *testing.T) {
+	originalFormat := Format
+	defer func() { Format = originalFormat }()
+	Format = "%.2f "
+
+	document := `upload: 50 MB
+cache: "1.5GB"
+buffer: 4096
+chunk: '512 KiB'`
+	expected := map[string]ByteSize{
+		"upload": 50 * MB,
+		"cache":  3 * GB / 2,
+		"buffer": 4 * KB,
+		"chunk":  512 * KB,
+	}
+
+	for _, line := range strings.Split(document, "\n") {
+		key, value, _ := strings.Cut(line, ": ")
+		var b ByteSize
+		if err := b.UnmarshalYAML(yamlScalar(value)); err != nil {
+			t.Errorf("UnmarshalYAML(%s) error = %v", value, err)
+			continue
+		}
+		if b != expected[key] {
+			t.Errorf("UnmarshalYAML(%s) = %d, expected %d", value, b, expected[key])
+		}
+	}
+
+	var b ByteSize
+	if err := b.UnmarshalYAML(yamlScalar("lots")); err == nil {
+		t.Error("UnmarshalYAML(lots): expected error")
+	}
+
+	out, err := (2 * MB).MarshalYAML()
+	if err != nil || out != "2.00 MB" {
+		t.Errorf("MarshalYAML() = %v, %v, expected 2.00 MB", out, err)
+	}
+	if err := b.UnmarshalYAML(yamlScalar(out.(string))); err != nil || b != 2*MB {
+		t.Errorf("YAML round trip = %d, %v", b, err)
+	}
+}
+
+func TestOrderedBytes(t *testing.T) {
+	sizes := []ByteSize{0, 1, 255, 256, KB, 65535, MB, 3 * GB / 2, 1 << 56, EB, math.MaxUint64}
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		sizes = append(sizes, ByteSize(rng.Uint64()>>rng.Intn(64)))
+	}
+	sort.Sort(BySize(sizes))
+
+	for i, b := range sizes {
+		encoded := b.OrderedBytes()
+		if decoded, err := FromOrderedBytes(encoded); err != nil || decoded != b {
+			t.Fatalf("FromOrderedBytes(%x) = %d, %v, expected %d", encoded, decoded, err, b)
+		}
+		if i > 0 {
+			prev := sizes[i-1].OrderedBytes()
+			if c := bytes.Compare(prev, encoded); c > 0 || c == 0 && sizes[i-1] != b {
+				t.Fatalf("encodings of %d and %d are out of order", sizes[i-1], b)
+			}
+		}
+	}
+
+	for _, data := range [][]byte{nil, {1, 2, 3}, make([]byte, 9)} {
+		if _, err := FromOrderedBytes(data); err == nil {
+			t.Errorf("FromOrderedBytes(%x): expected error", data)
+		}
+	}
+}
+
+func TestDecodeHook(t *testing.T) {
+	hook := DecodeHook()
+	to := reflect.TypeOf(ByteSize(0))
+
+	tests := []struct {
+		name     string
+		data     interface{}
+		expected ByteSize
+		fail     bool
+	}{
+		{"string", "512 MB", 512 * MB, false},
+		{"IEC string", "1.5GiB", 3 * GB / 2, false},
+		{"int", 4096, 4 * KB, false},
+		{"int64", int64(MB), MB, false},
+		{"uint64", uint64(math.MaxUint64), math.MaxUint64, false},
+		{"whole float", float64(2048), 2 * KB, false},
+		{"bad string", "lots", 0, true},
+		{"negative int", -1, 0, true},
+		{"fractional float", 1.5, 0, true},
+		{"negative float", -2.0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := hook(reflect.TypeOf(tt.data), to, tt.data)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("hook(%v): expected error, received %v", tt.data, out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("hook(%v) error = %v", tt.data, err)
+			}
+			if out != tt.expected {
+				t.Errorf("hook(%v) = %v, expected %d", tt.data, out, tt.expected)
+			}
+		})
+	}
+
+	// Values for other types are left for the decoder.
+	for _, target := range []interface{}{"", 0, time.Duration(0)} {
+		out, err := hook(reflect.TypeOf("1 MB"), reflect.TypeOf(target), "1 MB")
+		if err != nil || out != "1 MB" {
+			t.Errorf("hook into %T = %v, %v, expected the input unchanged", target, out, err)
+		}
+	}
+	out, err := hook(reflect.TypeOf(true), to, true)
+	if err != nil || out != true {
+		t.Errorf("hook(true) = %v, %v, expected the input unchanged", out, err)
+	}
+}
@@ -0,0 +1,92 @@
+package bytesize
+
+import "errors"
+
+// Sentinel errors returned, wrapped with the offending text and a localized
+// message, by Parse, ParseWithLocale and Parser.Parse. Test for them with
+// errors.Is.
+var (
+	// ErrEmptyInput means the input was empty or only whitespace.
+	ErrEmptyInput = errors.New("empty input")
+	// ErrNoUnit means the input has no unit suffix, as in "1024".
+	ErrNoUnit = errors.New("missing size suffix")
+	// ErrUnknownUnit means the unit suffix is not known, as in "1024 XB".
+	ErrUnknownUnit = errors.New("unrecognized size suffix")
+	// ErrInvalidNumber means the part before the unit is not a number.
+	ErrInvalidNumber = errors.New("invalid number")
+	// ErrNegativeSize means the number is negative, as in "-1 MB".
+	ErrNegativeSize = errors.New("negative size not allowed")
+	// ErrUnsupportedLocale means the locale is not supported.
+	ErrUnsupportedLocale = errors.New("unsupported locale")
+	// ErrInvalidEncoding means the input is not valid UTF-8.
+	ErrInvalidEncoding = errors.New("invalid UTF-8 encoding")
+)
+
+// Error codes used as keys of errorMessages and errorSentinels.
+const (
+	errEmptyInput        = "empty_input"
+	errUnsupportedLocale = "unsupported_locale"
+	errMissingSuffix     = "missing_suffix"
+	errUnknownSuffix     = "unknown_suffix"
+	errInvalidNumber     = "invalid_number"
+	errInvalidEncoding   = "invalid_encoding"
+	errNegativeSize      = "negative_size"
+)
+
+// errorMessages holds the parse error messages for each locale, keyed by
+// error code. Locales without an entry fall back to English.
+var errorMessages = map[Locale]map[string]string{
+	LocaleEN: {
+		errEmptyInput:        "empty input",
+		errUnsupportedLocale: "unsupported locale",
+		errMissingSuffix:     "unrecognized size suffix",
+		errUnknownSuffix:     "unrecognized size suffix",
+		errInvalidNumber:     "invalid number",
+		errInvalidEncoding:   "invalid UTF-8 encoding",
+		errNegativeSize:      "negative size not allowed",
+	},
+	LocaleRU: {
+		errEmptyInput:        "пустая строка",
+		errUnsupportedLocale: "неподдерживаемая локаль",
+		errMissingSuffix:     "нераспознанная единица измерения",
+		errUnknownSuffix:     "нераспознанная единица измерения",
+		errInvalidNumber:     "некорректное число",
+		errInvalidEncoding:   "некорректная кодировка UTF-8",
+		errNegativeSize:      "отрицательный размер недопустим",
+	},
+}
+
+// errorSentinels maps each error code to the sentinel error it wraps.
+var errorSentinels = map[string]error{
+	errEmptyInput:        ErrEmptyInput,
+	errUnsupportedLocale: ErrUnsupportedLocale,
+	errMissingSuffix:     ErrNoUnit,
+	errUnknownSuffix:     ErrUnknownUnit,
+	errInvalidNumber:     ErrInvalidNumber,
+	errInvalidEncoding:   ErrInvalidEncoding,
+	errNegativeSize:      ErrNegativeSize,
+}
+
+// localizedError is a parse error with a message in the locale of the
+// input. It unwraps to the sentinel error for its code.
+type localizedError struct {
+	msg      string
+	sentinel error
+}
+
+func (e *localizedError) Error() string { return e.msg }
+
+func (e *localizedError) Unwrap() error { return e.sentinel }
+
+// parseError returns the error for code in the given locale, followed by
+// detail if it is not empty.
+func parseError(locale Locale, code string, detail string) error {
+	msg, ok := errorMessages[locale][code]
+	if !ok {
+		msg = errorMessages[LocaleEN][code]
+	}
+	if detail != "" {
+		msg += ": " + detail
+	}
+	return &localizedError{msg: msg, sentinel: errorSentinels[code]}
+}
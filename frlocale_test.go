@@ -0,0 +1,85 @@
+package bytesize
+
+import "testing"
+
+func TestFrLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ByteSize
+	}{
+		{"Forme courte", "1 Go", GB},
+		{"Octets", "512 o", 512},
+		{"Octet au long", "1 octet", 1},
+		{"Sans trait d'union", "2 mégaoctets", 2 * MB},
+		{"Avec trait d'union", "2 méga-octets", 2 * MB},
+		{"Sans accent", "3 megaoctets", 3 * MB},
+		{"Kilo-octet", "1.5 kilo-octet", 3 * KB / 2},
+		{"Majuscules", "4 GIGA-OCTETS", 4 * GB},
+		{"Collé", "10Mo", 10 * MB},
+		{"IEC", "1 Gio", GB},
+		{"Unités anglaises", "2 MB", 2 * MB},
+		{"Espace fine insécable", "1\u202f024 Ko", 1024 * KB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseWithLocale(tt.input, LocaleFR)
+			if err != nil {
+				t.Errorf("ParseWithLocale(%q) error = %v", tt.input, err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("ParseWithLocale(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"1 kilo-", "1 -octet", "2 Xo"} {
+		if _, err := ParseWithLocale(input, LocaleFR); err == nil {
+			t.Errorf("ParseWithLocale(%q): expected error", input)
+		}
+	}
+}
+
+func TestFrenchFormatting(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	SetLocale(LocaleFR)
+
+	tests := []struct {
+		name      string
+		format    string
+		size      ByteSize
+		longUnits bool
+		expected  string
+	}{
+		{"0 octet", "%.0f ", New(0), true, "0 octet"},
+		{"1 octet", "%.0f ", New(1), true, "1 octet"},
+		{"2 octets", "%.0f ", New(2), true, "2 octets"},
+		{"1,5 kilo-octet", "%.1f ", 3 * KB / 2, true, "1.5 kilo-octet"},
+		{"2 méga-octets", "%.0f ", 2 * MB, true, "2 méga-octets"},
+		{"forme courte", "%.2f ", 3 * GB / 2, false, "1.50 Go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Format = tt.format
+			LongUnits = tt.longUnits
+			if result := tt.size.String(); result != tt.expected {
+				t.Errorf("Size %d String() = %q, expected %q", tt.size, result, tt.expected)
+			}
+		})
+	}
+
+	if result := (GB + 200*MB).BreakdownWords(LocaleFR, 0); result != "1 giga-octet et 200 méga-octets" {
+		t.Errorf("BreakdownWords() = %q", result)
+	}
+}
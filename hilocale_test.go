@@ -0,0 +1,70 @@
+package bytesize
+
+import "testing"
+
+func TestHiLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ByteSize
+	}{
+		{"Devanagari byte", "512 बाइट", 512},
+		{"Devanagari kilobyte", "2 किलोबाइट", 2 * KB},
+		{"Devanagari megabyte", "1.5 मेगाबाइट", ByteSize(1.5 * float64(MB))},
+		{"Devanagari gigabyte", "3 गीगाबाइट", 3 * GB},
+		{"Devanagari exabyte", "1 एक्साबाइट", EB},
+		{"Devanagari abbreviation", "4 जीबी", 4 * GB},
+		{"English short in Hindi", "2 KB", 2 * KB},
+		{"English long in Hindi", "2 megabytes", 2 * MB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseWithLocale(tt.input, LocaleHI)
+			if err != nil {
+				t.Errorf("ParseWithLocale(%q) error = %v", tt.input, err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("ParseWithLocale(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHindiFormatting(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	SetLocale(LocaleHI)
+	Format = "%.0f "
+
+	tests := []struct {
+		name      string
+		size      ByteSize
+		longUnits bool
+		expected  string
+	}{
+		{"1 byte", New(1), true, "1 बाइट"},
+		{"5 bytes", New(5), true, "5 बाइट"},
+		{"1 megabyte", MB, true, "1 मेगाबाइट"},
+		{"5 megabytes", 5 * MB, true, "5 मेगाबाइट"},
+		{"2 gigabytes", 2 * GB, true, "2 गीगाबाइट"},
+		{"short units", 2 * GB, false, "2 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			LongUnits = tt.longUnits
+			if result := tt.size.String(); result != tt.expected {
+				t.Errorf("Size %d String() = %q, expected %q", tt.size, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package bytesize
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// RangeHeader returns the Content-Range header value for the first b bytes
+// of a resource of total bytes, e.g. "bytes 0-499/1234". A b larger than
+// total is clamped to it, as the range cannot extend past the resource. If
+// that leaves no bytes, no range can be expressed and the unsatisfied form
+// "bytes */1234" is returned.
+func (b ByteSize) RangeHeader(total ByteSize) string {
+	b = min(b, total)
+	if b == 0 {
+		return "bytes */" + strconv.FormatUint(uint64(total), 10)
+	}
+	return "bytes 0-" + strconv.FormatUint(uint64(b-1), 10) + "/" + strconv.FormatUint(uint64(total), 10)
+}
+
+// ParseContentRange parses a Content-Range header value such as
+// "bytes 0-499/1234" into the first and last byte positions, both inclusive,
+// and the total size. A total of "*" means the size is unknown and is
+// returned as zero. The unsatisfied form "bytes */1234", which names no
+// bytes, returns only the total, with start and end zero.
+func ParseContentRange(s string) (start, end, total ByteSize, err error) {
+	s = strings.TrimSpace(s)
+	rest, ok := strings.CutPrefix(s, "bytes ")
+	if !ok {
+		return 0, 0, 0, errors.New("content range must start with \"bytes \"")
+	}
+
+	span, length, ok := strings.Cut(strings.TrimSpace(rest), "/")
+	if !ok {
+		return 0, 0, 0, errors.New("content range is missing the total size")
+	}
+	if span == "*" {
+		totalN, err := strconv.ParseUint(length, 10, 64)
+		if err != nil {
+			return 0, 0, 0, errors.New("invalid total size: " + strconv.Quote(length))
+		}
+		return 0, 0, ByteSize(totalN), nil
+	}
+	first, last, ok := strings.Cut(span, "-")
+	if !ok {
+		return 0, 0, 0, errors.New("content range is missing the byte range")
+	}
+
+	startN, err := strconv.ParseUint(first, 10, 64)
+	if err != nil {
+		return 0, 0, 0, errors.New("invalid range start: " + strconv.Quote(first))
+	}
+	endN, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return 0, 0, 0, errors.New("invalid range end: " + strconv.Quote(last))
+	}
+	if endN < startN {
+		return 0, 0, 0, errors.New("range end is before its start")
+	}
+
+	var totalN uint64
+	if length != "*" {
+		totalN, err = strconv.ParseUint(length, 10, 64)
+		if err != nil {
+			return 0, 0, 0, errors.New("invalid total size: " + strconv.Quote(length))
+		}
+		if endN >= totalN {
+			return 0, 0, 0, errors.New("range end is beyond the total size")
+		}
+	}
+	return ByteSize(startN), ByteSize(endN), ByteSize(totalN), nil
+}
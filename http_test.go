@@ -0,0 +1,74 @@
+package bytesize
+
+import "testing"
+
+func TestRangeHeader(t *testing.T) {
+	tests := []struct {
+		size     ByteSize
+		total    ByteSize
+		expected string
+	}{
+		{500, 1234, "bytes 0-499/1234"},
+		{KB, MB, "bytes 0-1023/1048576"},
+		{1, 1, "bytes 0-0/1"},
+		{0, 1234, "bytes */1234"},
+		{2000, 1000, "bytes 0-999/1000"},
+		{1000, 1000, "bytes 0-999/1000"},
+		{5, 0, "bytes */0"},
+	}
+
+	for _, tt := range tests {
+		if s := tt.size.RangeHeader(tt.total); s != tt.expected {
+			t.Errorf("(%d).RangeHeader(%d) = %q, expected %q", tt.size, tt.total, s, tt.expected)
+		}
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header            string
+		start, end, total ByteSize
+	}{
+		{"bytes 0-499/1234", 0, 499, 1234},
+		{"bytes 500-1233/1234", 500, 1233, 1234},
+		{" bytes 21010-47021/47022 ", 21010, 47021, 47022},
+		{"bytes 0-1023/*", 0, 1023, 0},
+		{"bytes */1234", 0, 0, 1234},
+	}
+
+	for _, tt := range tests {
+		start, end, total, err := ParseContentRange(tt.header)
+		if err != nil {
+			t.Errorf("ParseContentRange(%q) error = %v", tt.header, err)
+			continue
+		}
+		if start != tt.start || end != tt.end || total != tt.total {
+			t.Errorf("ParseContentRange(%q) = %d, %d, %d, expected %d, %d, %d",
+				tt.header, start, end, total, tt.start, tt.end, tt.total)
+		}
+	}
+
+	for _, header := range []string{
+		"", "0-499/1234", "bytes 0-499", "bytes 499/1234", "bytes */*", "bytes */big",
+		"bytes 500-499/1234", "bytes 0-1234/1234", "bytes a-1/2", "bytes 0-1/big",
+	} {
+		if _, _, _, err := ParseContentRange(header); err == nil {
+			t.Errorf("ParseContentRange(%q): expected error", header)
+		}
+	}
+
+	// RangeHeader output parses back.
+	for _, tt := range []struct {
+		size, total ByteSize
+		end         ByteSize
+	}{
+		{500, 1234, 499},
+		{0, 1234, 0},
+		{2000, 1000, 999},
+	} {
+		header := tt.size.RangeHeader(tt.total)
+		if start, end, total, err := ParseContentRange(header); err != nil || start != 0 || end != tt.end || total != tt.total {
+			t.Errorf("ParseContentRange(%q) = %d, %d, %d, %v", header, start, end, total, err)
+		}
+	}
+}
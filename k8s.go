@@ -0,0 +1,67 @@
+package bytesize
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// k8sSuffixes lists the Kubernetes quantity suffixes from largest to
+// smallest, binary before decimal.
+var k8sSuffixes = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"Ei", EB}, {"Pi", PB}, {"Ti", TB}, {"Gi", GB}, {"Mi", MB}, {"Ki", KB},
+	{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3},
+}
+
+// K8sQuantity returns b as a Kubernetes resource quantity such as "1Mi" or
+// "1536Ki". It uses the largest binary suffix that divides b exactly, then
+// the largest decimal one, and falls back to a plain byte count.
+func (b ByteSize) K8sQuantity() string {
+	if b != 0 {
+		for _, s := range k8sSuffixes {
+			if b%s.size == 0 {
+				return strconv.FormatUint(uint64(b/s.size), 10) + s.suffix
+			}
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10)
+}
+
+// ParseK8sQuantity parses a Kubernetes resource quantity such as "128Mi",
+// "1Gi", "500M" or "1e3". Suffixes are case-sensitive as in Kubernetes.
+// Fractional byte counts are rounded up.
+func ParseK8sQuantity(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("empty quantity")
+	}
+
+	unit := B
+	for _, suffix := range k8sSuffixes {
+		// An "E" followed by digits is an exponent, handled by ParseFloat.
+		if strings.HasSuffix(s, suffix.suffix) {
+			s, unit = strings.TrimSuffix(s, suffix.suffix), suffix.size
+			break
+		}
+	}
+	if s == "" || s[0] == '-' || s[0] == '+' {
+		return 0, errors.New("invalid quantity number")
+	}
+
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return TotalFor(unit, n)
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, errors.New("invalid quantity number: " + s)
+	}
+	bytes := math.Ceil(value * float64(unit))
+	if bytes >= math.MaxUint64 {
+		return 0, ErrOverflow
+	}
+	return ByteSize(bytes), nil
+}
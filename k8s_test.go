@@ -0,0 +1,63 @@
+package bytesize
+
+import "testing"
+
+func TestK8sQuantity(t *testing.T) {
+	tests := []struct {
+		size     ByteSize
+		expected string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{1023, "1023"},
+		{KB, "1Ki"},
+		{1536 * KB, "1536Ki"},
+		{MB, "1Mi"},
+		{128 * MB, "128Mi"},
+		{GB, "1Gi"},
+		{3 * EB, "3Ei"},
+		{1000, "1k"},
+		{500 * 1000 * 1000, "500M"},
+	}
+	for _, tt := range tests {
+		if q := tt.size.K8sQuantity(); q != tt.expected {
+			t.Errorf("(%d).K8sQuantity() = %q, expected %q", uint64(tt.size), q, tt.expected)
+		}
+		if b, err := ParseK8sQuantity(tt.expected); err != nil || b != tt.size {
+			t.Errorf("ParseK8sQuantity(%q) = %d, %v; expected %d", tt.expected, uint64(b), err, uint64(tt.size))
+		}
+	}
+}
+
+func TestParseK8sQuantity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+	}{
+		{"128Mi", 128 * MB},
+		{"1Gi", GB},
+		{"1.5Gi", 3 * GB / 2},
+		{"2k", 2000},
+		{"1e3", 1000},
+		{"1E3", 1000},
+		{"1E", 1e18},
+		{"0.5", 1},
+		{" 64Ki ", 64 * KB},
+	}
+	for _, tt := range tests {
+		b, err := ParseK8sQuantity(tt.input)
+		if err != nil {
+			t.Errorf("ParseK8sQuantity(%q) error = %v", tt.input, err)
+			continue
+		}
+		if b != tt.expected {
+			t.Errorf("ParseK8sQuantity(%q) = %d, expected %d", tt.input, uint64(b), uint64(tt.expected))
+		}
+	}
+
+	for _, input := range []string{"", "Mi", "-1Gi", "1GB", "1mi", "1K", "100m", "20Ei"} {
+		if _, err := ParseK8sQuantity(input); err == nil {
+			t.Errorf("ParseK8sQuantity(%q): expected error", input)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package bytesize
+
+import (
+	"errors"
+	"strings"
+)
+
+// constraintOps lists the comparison operators accepted by parseConstraint.
+// Two-character operators come first so they are matched before their
+// one-character prefixes.
+var constraintOps = []struct {
+	op   string
+	test func(b, limit ByteSize) bool
+}{
+	{">=", func(b, limit ByteSize) bool { return b >= limit }},
+	{"<=", func(b, limit ByteSize) bool { return b <= limit }},
+	{"!=", func(b, limit ByteSize) bool { return b != limit }},
+	{"==", func(b, limit ByteSize) bool { return b == limit }},
+	{">", func(b, limit ByteSize) bool { return b > limit }},
+	{"<", func(b, limit ByteSize) bool { return b < limit }},
+	{"=", func(b, limit ByteSize) bool { return b == limit }},
+}
+
+// parseConstraint parses a single comparison such as ">1GB" or "<= 10 MB".
+// A size without an operator matches exactly.
+func parseConstraint(s string) (func(ByteSize) bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty constraint")
+	}
+
+	test := constraintOps[len(constraintOps)-1].test
+	for _, c := range constraintOps {
+		if strings.HasPrefix(s, c.op) {
+			s, test = s[len(c.op):], c.test
+			break
+		}
+	}
+
+	limit, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return func(b ByteSize) bool { return test(b, limit) }, nil
+}
+
+// splitConstraints splits an alternative at the commas that start a new
+// comparison, i.e. that are followed by an operator or nothing, so that
+// decimal commas and digit groups such as ">1,5 ГБ" or ">1,024 KB" stay
+// part of their size.
+func splitConstraints(s string) []string {
+	var terms []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != ',' {
+			continue
+		}
+		if next := strings.TrimLeft(s[i+1:], " \t"); next == "" || strings.ContainsRune("<>=!", rune(next[0])) {
+			terms = append(terms, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(terms, s[start:])
+}
+
+// MatchSize reports whether b satisfies pattern. A pattern is a list of
+// alternatives separated by "|", each of which is a list of constraints
+// separated by "," that must all hold, e.g. ">1GB,<10GB" or "1GB|2GB".
+// Constraints are comparisons such as ">1GB" or "<=512MB", or a bare size
+// for an exact match. Only a comma followed by an operator separates
+// constraints, so sizes may contain decimal commas, as in ">1,5 ГБ" in the
+// Russian locale.
+func MatchSize(pattern string, b ByteSize) (bool, error) {
+	matched := false
+	for _, alternative := range strings.Split(pattern, "|") {
+		all := true
+		for _, term := range splitConstraints(alternative) {
+			test, err := parseConstraint(term)
+			if err != nil {
+				return false, err
+			}
+			all = all && test(b)
+		}
+		matched = matched || all
+	}
+	return matched, nil
+}
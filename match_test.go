@@ -0,0 +1,73 @@
+package bytesize
+
+import "testing"
+
+func TestMatchSize(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		size     ByteSize
+		expected bool
+	}{
+		{">1GB,<10GB", 5 * GB, true},
+		{">1GB,<10GB", GB, false},
+		{">1GB,<10GB", 10 * GB, false},
+		{">=1GB,<=10GB", 10 * GB, true},
+		{"1GB|2GB", 2 * GB, true},
+		{"1GB|2GB", 3 * GB, false},
+		{"<1MB|>1GB,<2GB", 512 * KB, true},
+		{"<1MB|>1GB,<2GB", 3 * GB / 2, true},
+		{"<1MB|>1GB,<2GB", 500 * MB, false},
+		{"!= 0 B", 1, true},
+		{"== 1 KB", KB, true},
+		{" = 1 KB ", KB, true},
+		{">1,024 KB", MB, false},
+		{">1,024 KB", MB + 1, true},
+		{">1GB, <10GB", 5 * GB, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matched, err := MatchSize(tt.pattern, tt.size)
+			if err != nil {
+				t.Fatalf("MatchSize(%q) error = %v", tt.pattern, err)
+			}
+			if matched != tt.expected {
+				t.Errorf("MatchSize(%q, %s) = %t, expected %t", tt.pattern, tt.size, matched, tt.expected)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"", ">1GB,", ">1GB, ", "1GB||2GB", ">potato", "~1GB", "1GB,2GB"} {
+		if _, err := MatchSize(pattern, GB); err == nil {
+			t.Errorf("MatchSize(%q): expected error", pattern)
+		}
+	}
+}
+
+func TestMatchSizeDecimalComma(t *testing.T) {
+	originalLocale := CurrentLocale
+	defer func() { CurrentLocale = originalLocale }()
+	SetLocale(LocaleRU)
+
+	tests := []struct {
+		pattern  string
+		size     ByteSize
+		expected bool
+	}{
+		{">1,5 ГБ", 2 * GB, true},
+		{">1,5 ГБ", GB, false},
+		{">1,5 ГБ,<2,5 ГБ", 2 * GB, true},
+		{">1,5 ГБ, <2,5 ГБ", 3 * GB, false},
+		{"<0,5 МБ|>1,5 ГБ", 256 * KB, true},
+	}
+
+	for _, tt := range tests {
+		matched, err := MatchSize(tt.pattern, tt.size)
+		if err != nil {
+			t.Fatalf("MatchSize(%q) error = %v", tt.pattern, err)
+		}
+		if matched != tt.expected {
+			t.Errorf("MatchSize(%q, %s) = %t, expected %t", tt.pattern, tt.size, matched, tt.expected)
+		}
+	}
+}
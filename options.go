@@ -0,0 +1,226 @@
+package bytesize
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundMode selects how the displayed value is rounded to the precision of
+// the format.
+type RoundMode int
+
+const (
+	// RoundNearest rounds to the nearest displayable value, as fmt does.
+	RoundNearest RoundMode = iota
+	// RoundDown never shows more than the actual size, e.g. "1.99 KB" for
+	// 2047 bytes.
+	RoundDown
+	// RoundUp never shows less than the actual size, e.g. "1.01 KB" for
+	// 1025 bytes.
+	RoundUp
+)
+
+// FormatOptions controls how FormatOpts renders a size. The zero value
+// renders like String with the default globals, in English.
+type FormatOptions struct {
+	// Locale selects the unit names. An unsupported locale falls back to
+	// English.
+	Locale Locale
+	// Format is the printf-style format for the value, with the same rules
+	// as the Format global. An empty or invalid format renders as "%.2f ".
+	Format string
+	// LongUnits selects long unit names, e.g. "megabytes" instead of "MB".
+	LongUnits bool
+	// SpaceBetween adds a space between the value and the unit when Format
+	// does not already end with one.
+	SpaceBetween bool
+	// ForcedUnit, if not zero, is the unit constant the value is shown in
+	// instead of the automatically selected one.
+	ForcedUnit ByteSize
+	// UnitSystem selects binary or decimal prefixes.
+	UnitSystem UnitSystem
+	// IECUnits selects IEC short units such as "MiB" in the binary system.
+	IECUnits bool
+	// HighMagnitudeExtraDecimals adds decimals to values shown in TB or
+	// larger units, as the global of the same name does.
+	HighMagnitudeExtraDecimals int
+	// RoundMode selects how the value is rounded. It only applies to the
+	// fixed-point verbs "%f" and "%F".
+	RoundMode RoundMode
+	// Grouping groups the integer part of the value by thousands and uses
+	// the locale's decimal separator, e.g. "1 010,00 МБ" in Russian.
+	Grouping bool
+	// TrimZeros drops trailing zeros after the decimal point, and the point
+	// itself if nothing is left, as the global of the same name does.
+	TrimZeros bool
+}
+
+// Options is another name for FormatOptions, for use with FormatWith.
+type Options = FormatOptions
+
+// FormatWith returns the string form of b rendered with opts. It is the
+// same as FormatOpts: for options mirroring the package globals, the output
+// is identical to String.
+func (b ByteSize) FormatWith(opts Options) string {
+	return b.FormatOpts(opts)
+}
+
+// optionsFromGlobals returns the FormatOptions String uses, taken from the
+// package globals.
+func optionsFromGlobals() FormatOptions {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return FormatOptions{
+		Locale:                     CurrentLocale,
+		Format:                     Format,
+		LongUnits:                  LongUnits,
+		UnitSystem:                 CurrentSystem,
+		IECUnits:                   UseIECUnits,
+		HighMagnitudeExtraDecimals: HighMagnitudeExtraDecimals,
+		TrimZeros:                  TrimZeros,
+	}
+}
+
+// FormatOpts returns the string form of b rendered with opts. Unlike String
+// it does not read the package globals, except for the unit ladder, so it is
+// safe to use with per-call settings.
+func (b ByteSize) FormatOpts(opts FormatOptions) string {
+	units, ok := localizedUnits[opts.Locale]
+	if !ok {
+		units = localizedUnits[LocaleEN]
+	}
+
+	format := sanitizeFormat(opts.Format)
+	if opts.SpaceBetween && !strings.HasSuffix(format, " ") {
+		format += " "
+	}
+
+	unit := opts.ForcedUnit
+	if unit == 0 {
+		unit = b.autoUnitIn(opts.UnitSystem)
+	} else if _, known := units.shortUnits[unit]; !known {
+		return "Unrecognized unit: " + strconv.FormatUint(uint64(unit), 10)
+	}
+
+	value := float64(b) / float64(opts.UnitSystem.unitBytes(unit))
+
+	if unit >= TB && opts.HighMagnitudeExtraDecimals > 0 {
+		format = addPrecision(format, opts.HighMagnitudeExtraDecimals)
+	}
+	if opts.RoundMode != RoundNearest {
+		value = roundValue(value, format, opts.RoundMode)
+	}
+
+	number := fmt.Sprintf(format, value)
+	// Plural forms follow the number as shown, so 1025 bytes in "%.0f"
+	// read "1 kilobyte" rather than "1 kilobytes".
+	shown := displayedValue(number, value)
+	if opts.TrimZeros {
+		number = trimZeros(number)
+	}
+	if opts.Grouping {
+		number = localizeNumber(number, units)
+	} else if units.groupSeparator == "." {
+		// A decimal point would be read back as a thousands separator, as
+		// in German "1.500", so use the locale's decimal separator without
+		// grouping the digits.
+		ungrouped := units
+		ungrouped.groupSeparator = ""
+		number = localizeNumber(number, ungrouped)
+	}
+
+	if opts.LongUnits {
+		return number + longUnitName(shown, unit, units)
+	}
+	return number + shortUnitName(unit, units, opts.UnitSystem, opts.IECUnits)
+}
+
+// displayedValue returns the first number in s, the output of formatting
+// value, as fmt rounded it. If s contains no number, value is returned.
+func displayedValue(s string, value float64) float64 {
+	start := strings.IndexAny(s, "0123456789")
+	if start < 0 {
+		return value
+	}
+	number, _ := splitNumber(s[start:], "", ".")
+	shown, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return value
+	}
+	return shown
+}
+
+// trimZeros removes the trailing zeros of the first fraction in s, and its
+// decimal point if no digits remain: "1.50 " becomes "1.5 " and "2.00"
+// becomes "2".
+func trimZeros(s string) string {
+	dot := strings.IndexByte(s, '.')
+	if dot < 0 {
+		return s
+	}
+	end := dot + 1
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	cut := end
+	for cut > dot+1 && s[cut-1] == '0' {
+		cut--
+	}
+	if cut == dot+1 {
+		cut = dot
+	}
+	return s[:cut] + s[end:]
+}
+
+// roundValue rounds value down or up to the number of decimals format
+// shows, so that fmt's own rounding leaves it unchanged. Formats other than
+// "%f" and "%F" are not affected.
+func roundValue(value float64, format string, mode RoundMode) float64 {
+	precision, ok := fixedPrecision(format)
+	if !ok {
+		return value
+	}
+	step := math.Pow(10, -float64(precision))
+	nearest, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'f', precision, 64), 64)
+	switch {
+	case mode == RoundDown && nearest > value:
+		return math.Max(nearest-step, 0)
+	case mode == RoundUp && nearest < value:
+		return nearest + step
+	}
+	return nearest
+}
+
+// fixedPrecision returns the number of decimals a "%f" or "%F" verb in
+// format shows. It reports false for other verbs.
+func fixedPrecision(format string) (int, bool) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789", format[j]) >= 0 {
+			j++
+		}
+		precision := 6
+		if j < len(format) && format[j] == '.' {
+			j++
+			start := j
+			for j < len(format) && format[j] >= '0' && format[j] <= '9' {
+				j++
+			}
+			precision, _ = strconv.Atoi(format[start:j])
+		}
+		if j < len(format) && (format[j] == 'f' || format[j] == 'F') {
+			return precision, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package bytesize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatOpts(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     ByteSize
+		opts     FormatOptions
+		expected string
+	}{
+		{"zero options", 3 * MB / 2, FormatOptions{}, "1.50 MB"},
+		{"custom format", 3 * MB / 2, FormatOptions{Format: "%.1f"}, "1.5MB"},
+		{"space between", 3 * MB / 2, FormatOptions{Format: "%.1f", SpaceBetween: true}, "1.5 MB"},
+		{"space already there", 3 * MB / 2, FormatOptions{Format: "%.1f ", SpaceBetween: true}, "1.5 MB"},
+		{"long units", 2 * GB, FormatOptions{LongUnits: true}, "2.00 gigabytes"},
+		{"russian", 5 * MB, FormatOptions{Locale: LocaleRU, LongUnits: true, Format: "%.0f "}, "5 мегабайтов"},
+		{"unknown locale", 2 * KB, FormatOptions{Locale: "xx", LongUnits: true}, "2.00 kilobytes"},
+		{"forced unit", 3 * MB / 2, FormatOptions{ForcedUnit: KB}, "1536.00 KB"},
+		{"unknown forced unit", MB, FormatOptions{ForcedUnit: 1000}, "Unrecognized unit: 1000"},
+		{"decimal system", 1500000, FormatOptions{UnitSystem: SystemDecimal}, "1.50 MB"},
+		{"IEC units", 3 * MB / 2, FormatOptions{IECUnits: true}, "1.50 MiB"},
+		{"extra decimals", 3 * TB / 2, FormatOptions{HighMagnitudeExtraDecimals: 2}, "1.5000 TB"},
+		{"round nearest", 2047, FormatOptions{}, "2.00 KB"},
+		{"round down", 2047, FormatOptions{RoundMode: RoundDown}, "1.99 KB"},
+		{"round up", 1025, FormatOptions{RoundMode: RoundUp}, "1.01 KB"},
+		{"round up exact", 3 * KB / 2, FormatOptions{RoundMode: RoundUp}, "1.50 KB"},
+		{"round down whole", 2047, FormatOptions{Format: "%.0f ", RoundMode: RoundDown}, "1 KB"},
+		{"round mode ignores %g", 2047, FormatOptions{Format: "%.3g ", RoundMode: RoundDown}, "2 KB"},
+		{"trim zeros whole", KB, FormatOptions{TrimZeros: true}, "1 KB"},
+		{"trim zeros fraction", 3 * MB / 2, FormatOptions{TrimZeros: true}, "1.5 MB"},
+		{"trim zeros keeps digits", 1500000, FormatOptions{Format: "%.2f", TrimZeros: true}, "1.43MB"},
+		{"trim zeros grouped", 1010 * MB, FormatOptions{Locale: LocaleRU, Grouping: true, TrimZeros: true}, "1\u2009010 МБ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.size.FormatOpts(tt.opts); result != tt.expected {
+				t.Errorf("FormatOpts(%+v) = %q, expected %q", tt.opts, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatOptsIgnoresGlobals(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	SetLocale(LocaleRU)
+	LongUnits = true
+	Format = "%.0f "
+	if result := (2 * MB).FormatOpts(FormatOptions{}); result != "2.00 MB" {
+		t.Errorf("FormatOpts read the globals: %q", result)
+	}
+
+	// Format and String go through the same renderer.
+	if result := (2 * MB).Format("%.1f ", "KB", false); result != "2048.0 КБ" {
+		t.Errorf("Format() = %q", result)
+	}
+	if result := (2 * MB).String(); result != "2 мегабайта" {
+		t.Errorf("String() = %q", result)
+	}
+}
+
+func TestFormatWithMatchesGlobals(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	originalSystem := CurrentSystem
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+		CurrentSystem = originalSystem
+	}()
+
+	sizes := []ByteSize{0, 1, 999, KB, 1500, 3 * MB / 2, 21 * GB, 5 * PB, math.MaxUint64}
+	for _, locale := range []Locale{LocaleEN, LocaleRU, LocaleHI, LocaleDE, LocaleFR, LocalePL} {
+		for _, format := range []string{"%.2f ", "%.0f", "%e "} {
+			for _, longUnits := range []bool{false, true} {
+				for _, system := range []UnitSystem{SystemBinary, SystemDecimal} {
+					CurrentLocale, Format, LongUnits, CurrentSystem = locale, format, longUnits, system
+					opts := Options{Format: format, LongUnits: longUnits, Locale: locale, UnitSystem: system}
+					for _, size := range sizes {
+						if got, want := size.FormatWith(opts), size.String(); got != want {
+							t.Errorf("FormatWith(%+v) of %d = %q, String() = %q", opts, uint64(size), got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package bytesize
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parser parses byte size strings for a single locale. Unlike Parse it
+// resolves the locale and its options once, in NewParser, so later changes
+// to the package globals do not affect it. NewParser also precompiles the
+// suffixes, so that plain inputs such as "1.5 GB" skip the upper-casing and
+// number scanning Parse does; this makes a Parser the better choice for bulk
+// parsing. A Parser is safe for concurrent use.
+type Parser struct {
+	locale Locale
+	units  unitDefinitions
+	// lookup maps upper-case suffixes, including enabled synonyms, to units.
+	lookup map[string]ByteSize
+	// suffixes holds the ASCII entries of lookup, longest first, for the
+	// fast path.
+	suffixes     []parserSuffix
+	tolerateRate bool
+	system       UnitSystem
+}
+
+// parserSuffix is an upper-case ASCII suffix and the number of bytes it
+// stands for in the Parser's unit system.
+type parserSuffix struct {
+	name  string
+	bytes ByteSize
+}
+
+// ParserOption configures a Parser created by NewParser.
+type ParserOption func(*Parser)
+
+// WithSynonyms makes the Parser accept the informal unit names enabled
+// globally by ParseSynonyms, e.g. "meg" or "gig".
+func WithSynonyms() ParserOption {
+	return func(p *Parser) {
+		configMu.RLock()
+		defer configMu.RUnlock()
+		for name, unit := range parseSynonyms {
+			if _, ok := p.lookup[name]; !ok {
+				p.lookup[name] = unit
+			}
+		}
+	}
+}
+
+// WithRateSuffix makes the Parser ignore a trailing rate suffix such as
+// "/s", like TolerateRateSuffix does for Parse.
+func WithRateSuffix() ParserOption {
+	return func(p *Parser) { p.tolerateRate = true }
+}
+
+// WithUnitSystem makes the Parser interpret unit prefixes in system, like
+// CurrentSystem does for Parse. Without it a Parser uses SystemBinary.
+func WithUnitSystem(system UnitSystem) ParserOption {
+	return func(p *Parser) { p.system = system }
+}
+
+// NewParser returns a Parser for locale configured by opts. The package
+// globals do not affect the returned Parser. It returns an error if the
+// locale is not supported.
+func NewParser(locale Locale, opts ...ParserOption) (*Parser, error) {
+	units, ok := localizedUnits[locale]
+	if !ok {
+		return nil, parseError(locale, errUnsupportedLocale, string(locale))
+	}
+
+	p := &Parser{
+		locale: locale,
+		units:  units,
+		lookup: make(map[string]ByteSize, len(units.parseMap)),
+	}
+	for suffix, unit := range units.parseMap {
+		p.lookup[suffix] = unit
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for suffix, unit := range p.lookup {
+		if isASCII(suffix) {
+			p.suffixes = append(p.suffixes, parserSuffix{suffix, p.system.parsedUnitBytes(unit, suffix, units)})
+		}
+	}
+	sort.Slice(p.suffixes, func(i, j int) bool {
+		a, b := p.suffixes[i].name, p.suffixes[j].name
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return p, nil
+}
+
+// Parse parses s like ParseWithLocale does for the Parser's locale.
+func (p *Parser) Parse(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	if p.tolerateRate {
+		s = stripRateSuffix(s)
+	}
+	if size, ok, err := p.parseFast(s); ok {
+		return size, err
+	}
+	return parseSize(s, p.locale, p.units, p.system, p.find)
+}
+
+// parseFast parses s if it is a plain ASCII number, optionally with a
+// decimal point, followed by a precompiled suffix. It reports false for
+// everything else, which is left to parseSize, so that both paths accept
+// the same inputs and report the same errors.
+func (p *Parser) parseFast(s string) (size ByteSize, ok bool, err error) {
+	n := len(s)
+	if n < 2 {
+		return 0, false, nil
+	}
+	last := upperASCII(s[n-1])
+	for _, suffix := range p.suffixes {
+		l := len(suffix.name)
+		if l >= n || suffix.name[l-1] != last || !equalFoldASCII(s[n-l:], suffix.name) {
+			continue
+		}
+		// The longest matching suffix decides; if the rest is not a plain
+		// number, the general path handles it.
+		number := strings.TrimRight(s[:n-l], " ")
+		if !p.isPlainNumber(number) {
+			return 0, false, nil
+		}
+		size, err := scaleNumber(number, suffix.bytes)
+		return size, true, err
+	}
+	return 0, false, nil
+}
+
+// isPlainNumber reports whether s is digits with at most one decimal point
+// between them. A point is not plain in locales that group thousands with
+// it, where "1.500" means 1500.
+func (p *Parser) isPlainNumber(s string) bool {
+	if s == "" || s[0] < '0' || s[0] > '9' || s[len(s)-1] < '0' || s[len(s)-1] > '9' {
+		return false
+	}
+	seenDot := false
+	for i := 1; i < len(s)-1; i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+		case c == '.' && !seenDot && p.units.groupSeparator != ".":
+			seenDot = true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isASCII reports whether s contains only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// upperASCII returns c upper-cased if it is an ASCII letter.
+func upperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
+
+// equalFoldASCII reports whether s equals upper, an upper-case ASCII
+// string, ignoring the case of ASCII letters.
+func equalFoldASCII(s, upper string) bool {
+	if len(s) != len(upper) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if upperASCII(s[i]) != upper[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the unit for an upper-case suffix.
+func (p *Parser) find(suffix string) (ByteSize, bool) {
+	unit, ok := p.lookup[suffix]
+	return unit, ok
+}
@@ -0,0 +1,94 @@
+package bytesize
+
+import "testing"
+
+func TestParser(t *testing.T) {
+	inputs := []string{
+		"1 KB", "1.5GB", " 512 bytes ", "2 KiB", "1,024 MB", "1e3 B",
+		"16.00 EB", "20 EB", "3 XB", "42", "", "KB", "1.2.3 MB",
+		"1.500 MB", "1,5 MB", "10 kib", "7 eb", "5 XKB", "1. MB", ".5 MB",
+		"1\u00a0MB", "00012 KB", "2 kilo-octets", "3 bajty", "+1 KB", "1e3",
+	}
+
+	for _, locale := range []Locale{LocaleEN, LocaleRU, LocaleHI, LocaleDE, LocaleFR, LocalePL} {
+		p, err := NewParser(locale)
+		if err != nil {
+			t.Fatalf("NewParser(%s) error = %v", locale, err)
+		}
+		for _, input := range append(inputs, "2 ГБ", "1 мегабайт", "3 केबी") {
+			got, gotErr := p.Parse(input)
+			want, wantErr := ParseWithLocale(input, locale)
+			if got != want || (gotErr == nil) != (wantErr == nil) {
+				t.Errorf("%s: Parser.Parse(%q) = %d, %v; ParseWithLocale = %d, %v", locale, input, got, gotErr, want, wantErr)
+			}
+			if gotErr != nil && wantErr != nil && gotErr.Error() != wantErr.Error() {
+				t.Errorf("%s: Parser.Parse(%q) error %q, expected %q", locale, input, gotErr, wantErr)
+			}
+		}
+	}
+
+	originalSystem := getSystem()
+	defer SetUnitSystem(originalSystem)
+	SetUnitSystem(SystemDecimal)
+	decimal, _ := NewParser(LocaleEN, WithUnitSystem(SystemDecimal))
+	for _, input := range []string{"1 KB", "1 kB", "1 KiB", "1.5 MiB", "2 GB"} {
+		got, gotErr := decimal.Parse(input)
+		want, wantErr := Parse(input)
+		if got != want || gotErr != wantErr {
+			t.Errorf("decimal Parser.Parse(%q) = %d, %v; Parse = %d, %v", input, got, gotErr, want, wantErr)
+		}
+	}
+
+	if _, err := NewParser(Locale("xx")); err == nil {
+		t.Error("NewParser accepted an unsupported locale")
+	}
+}
+
+func TestParserOptions(t *testing.T) {
+	originalSynonyms := ParseSynonyms
+	originalRate := TolerateRateSuffix
+	defer func() {
+		ParseSynonyms = originalSynonyms
+		TolerateRateSuffix = originalRate
+	}()
+
+	plain, _ := NewParser(LocaleEN)
+	tolerant, _ := NewParser(LocaleEN, WithSynonyms(), WithRateSuffix())
+
+	// The globals must not leak into a Parser.
+	ParseSynonyms, TolerateRateSuffix = true, true
+	for _, input := range []string{"10 gig", "10 MB/s"} {
+		if _, err := plain.Parse(input); err == nil {
+			t.Errorf("plain Parser accepted %q", input)
+		}
+	}
+	ParseSynonyms, TolerateRateSuffix = false, false
+
+	tests := []struct {
+		input    string
+		expected ByteSize
+	}{
+		{"10 gig", 10 * GB},
+		{"3meg", 3 * MB},
+		{"10 MB/s", 10 * MB},
+		{"1 KB", KB},
+	}
+	for _, tt := range tests {
+		if b, err := tolerant.Parse(tt.input); err != nil || b != tt.expected {
+			t.Errorf("Parse(%q) = %d, %v, expected %d", tt.input, b, err, tt.expected)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Parse("1.5 GB")
+	}
+}
+
+func BenchmarkParserParse(b *testing.B) {
+	p, _ := NewParser(LocaleEN)
+	for i := 0; i < b.N; i++ {
+		p.Parse("1.5 GB")
+	}
+}
@@ -0,0 +1,119 @@
+package bytesize
+
+import "testing"
+
+func TestPlLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ByteSize
+	}{
+		{"Forma krótka", "2 KB", 2 * KB},
+		{"Bajt", "1 bajt", 1},
+		{"Bajty", "3 bajty", 3},
+		{"Bajtów", "5 bajtów", 5},
+		{"Bez polskich znaków", "5 bajtow", 5},
+		{"Megabajty", "2 megabajty", 2 * MB},
+		{"Dopełniacz", "1.5 gigabajta", 3 * GB / 2},
+		{"Wielkie litery", "7 EKSABAJTÓW", 7 * EB},
+		{"Spacja nierozdzielająca", "1\u00a0024 KB", 1024 * KB},
+		{"Jednostki angielskie", "2 megabytes", 2 * MB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseWithLocale(tt.input, LocalePL)
+			if err != nil {
+				t.Errorf("ParseWithLocale(%q) error = %v", tt.input, err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("ParseWithLocale(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPolishPlurals(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	SetLocale(LocalePL)
+	LongUnits = true
+	Format = "%.0f "
+
+	tests := []struct {
+		size     ByteSize
+		expected string
+	}{
+		{1, "1 bajt"},
+		{2, "2 bajty"},
+		{4, "4 bajty"},
+		{5, "5 bajtów"},
+		{12, "12 bajtów"},
+		{22, "22 bajty"},
+		{112, "112 bajtów"},
+		{0, "0 bajtów"},
+		{MB, "1 megabajt"},
+		{22 * GB, "22 gigabajty"},
+		{5 * EB, "5 eksabajtów"},
+		{KB + 1, "1 kilobajt"},
+		{2*KB - 1, "2 kilobajty"},
+		{5*KB + 100, "5 kilobajtów"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.size.String(); result != tt.expected {
+			t.Errorf("Size %d String() = %q, expected %q", tt.size, result, tt.expected)
+		}
+	}
+
+	LongUnits = false
+	if result := (2 * GB).String(); result != "2 GB" {
+		t.Errorf("short units String() = %q, expected %q", result, "2 GB")
+	}
+	if result := (GB + 200*MB).BreakdownWords(LocalePL, 0); result != "1 gigabajt i 200 megabajtów" {
+		t.Errorf("BreakdownWords() = %q", result)
+	}
+}
+
+func TestPolishFractionalPlurals(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	SetLocale(LocalePL)
+	LongUnits = true
+	Format = "%.2f "
+
+	// Ułamki łączą się z dopełniaczem liczby pojedynczej: "1,5 kilobajta".
+	// Liczy się liczba wyświetlona, a nie dokładna wartość.
+	tests := []struct {
+		size     ByteSize
+		expected string
+	}{
+		{3 * KB / 2, "1.50 kilobajta"},
+		{5*MB + MB/4, "5.25 megabajta"},
+		{2 * GB, "2.00 gigabajty"},
+		{5 * EB, "5.00 eksabajtów"},
+		{KB + 1, "1.00 kilobajt"},
+		{2*KB - 1, "2.00 kilobajty"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.size.String(); result != tt.expected {
+			t.Errorf("Size %d String() = %q, expected %q", tt.size, result, tt.expected)
+		}
+	}
+}
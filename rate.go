@@ -0,0 +1,184 @@
+package bytesize
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// rateSuffixes maps the time units accepted after a "/" in a rate to their
+// durations. Keys are upper case.
+var rateSuffixes = map[string]time.Duration{
+	"MS":  time.Millisecond,
+	"S":   time.Second,
+	"SEC": time.Second,
+	"M":   time.Minute,
+	"MIN": time.Minute,
+	"H":   time.Hour,
+	"HR":  time.Hour,
+	"D":   24 * time.Hour,
+	"DAY": 24 * time.Hour,
+}
+
+// stripRateSuffix removes a trailing "/<time unit>" from s, if present.
+func stripRateSuffix(s string) string {
+	i := strings.LastIndexByte(s, '/')
+	if i < 0 {
+		return s
+	}
+	if _, ok := rateSuffixes[strings.ToUpper(strings.TrimSpace(s[i+1:]))]; !ok {
+		return s
+	}
+	return strings.TrimSpace(s[:i])
+}
+
+// Growth is a signed change in size over time, as returned by GrowthRate.
+type Growth struct {
+	// Rate is the speed of the change, whichever its direction.
+	Rate Rate
+	// Shrinking reports whether the size decreased.
+	Shrinking bool
+}
+
+// GrowthRate returns the change from old to new over the given duration.
+// Shrinkage is reported with Shrinking set rather than wrapping around.
+func GrowthRate(old, new ByteSize, over time.Duration) Growth {
+	if new >= old {
+		return Growth{Rate: NewRate(new-old, over)}
+	}
+	return Growth{Rate: NewRate(old-new, over), Shrinking: true}
+}
+
+// PerSecond returns the growth in bytes per second, negative when the size
+// shrank. A non-positive duration yields zero.
+func (g Growth) PerSecond() float64 {
+	if g.Shrinking {
+		return -g.Rate.PerSecond()
+	}
+	return g.Rate.PerSecond()
+}
+
+// String returns the growth per hour using the package global options,
+// with an explicit sign, e.g. "+1.20 MB/h" or "-512.00 KB/h". Growth too
+// fast for a ByteSize per hour saturates.
+func (g Growth) String() string {
+	sign := "+"
+	if g.Shrinking && g.Rate.PerSecond() > 0 {
+		sign = "-"
+	}
+	return sign + saturatingBytes(g.Rate.PerSecond()*time.Hour.Seconds()).String() + "/h"
+}
+
+// TransferTime returns how long transferring b takes at bytesPerSec. It
+// returns an error if the rate is not a positive number. Durations too long
+// for time.Duration are capped at its maximum.
+func (b ByteSize) TransferTime(bytesPerSec float64) (time.Duration, error) {
+	if !(bytesPerSec > 0) {
+		return 0, errors.New("transfer rate must be positive")
+	}
+	seconds := float64(b) / bytesPerSec
+	if seconds >= float64(math.MaxInt64)/float64(time.Second) {
+		return math.MaxInt64, nil
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// AtRate returns how long transferring b takes at bytesPerSec. It returns
+// zero if the rate is not positive; use TransferTime to detect that case.
+func (b ByteSize) AtRate(bytesPerSec float64) time.Duration {
+	d, _ := b.TransferTime(bytesPerSec)
+	return d
+}
+
+// Rate is a throughput: Amount bytes transferred every Per. It is shown and
+// compared per second, so 600 KB per minute and 10 KB per second are equal
+// rates.
+type Rate struct {
+	Amount ByteSize
+	Per    time.Duration
+}
+
+// NewRate returns the rate of amount bytes every per.
+func NewRate(amount ByteSize, per time.Duration) Rate {
+	return Rate{Amount: amount, Per: per}
+}
+
+// PerSecond returns r in bytes per second. A non-positive Per yields zero.
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Amount) / r.Per.Seconds()
+}
+
+// SortKey returns r in whole bytes per second, a key that orders rates by
+// speed like ByteSize.SortKey orders sizes. Rates too fast for a uint64
+// saturate.
+func (r Rate) SortKey() uint64 {
+	return uint64(saturatingBytes(r.PerSecond()))
+}
+
+// saturatingBytes returns the non-negative bytes rounded to the nearest
+// byte, or the largest ByteSize if they do not fit in one.
+func saturatingBytes(bytes float64) ByteSize {
+	bytes = math.Round(bytes)
+	if bytes >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return ByteSize(bytes)
+}
+
+// String returns r per second using the package global options, e.g.
+// "10.00 MB/s".
+func (r Rate) String() string {
+	return ByteSize(r.SortKey()).String() + "/s"
+}
+
+// Parse parses a rate such as "10 MB/s" or "600 KB/min" and sets r to it.
+// The size is parsed like Parse and the time unit after the "/" may be any
+// of "ms", "s", "sec", "m", "min", "h", "hr", "d" or "day".
+func (r *Rate) Parse(s string) error {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndexByte(s, '/')
+	if i < 0 {
+		return errors.New("missing \"/\" and time unit")
+	}
+	per, ok := rateSuffixes[strings.ToUpper(strings.TrimSpace(s[i+1:]))]
+	if !ok {
+		return errors.New("unrecognized time unit: " + strings.TrimSpace(s[i+1:]))
+	}
+	amount, err := Parse(s[:i])
+	if err != nil {
+		return err
+	}
+	*r = Rate{Amount: amount, Per: per}
+	return nil
+}
+
+// UnmarshalText parses text with Parse and sets the value of r.
+// It implements the encoding.TextUnmarshaler interface.
+func (r *Rate) UnmarshalText(text []byte) error {
+	return r.Parse(string(text))
+}
+
+// MarshalText returns the form of r that UnmarshalText restores exactly:
+// the MarshalText form of Amount followed by the time unit Per stands for,
+// e.g. "10.00 MB/s" or "600.00 KB/min". It returns an error if Per is not
+// exactly a millisecond, second, minute, hour or day. It implements the
+// encoding.TextMarshaler interface.
+func (r Rate) MarshalText() ([]byte, error) {
+	amount, err := r.Amount.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	for _, unit := range []struct {
+		name string
+		d    time.Duration
+	}{{"d", 24 * time.Hour}, {"h", time.Hour}, {"min", time.Minute}, {"s", time.Second}, {"ms", time.Millisecond}} {
+		if r.Per == unit.d {
+			return append(amount, "/"+unit.name...), nil
+		}
+	}
+	return nil, errors.New("rate window " + r.Per.String() + " is not a single time unit")
+}
@@ -0,0 +1,218 @@
+package bytesize
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGrowthRate(t *testing.T) {
+	originFormat := Format
+	originLongUnits := LongUnits
+	originLocale := CurrentLocale
+	defer func() {
+		Format = originFormat
+		LongUnits = originLongUnits
+		CurrentLocale = originLocale
+	}()
+	Format = "%.1f "
+	LongUnits = false
+	CurrentLocale = LocaleEN
+
+	tests := []struct {
+		name      string
+		old, new  ByteSize
+		over      time.Duration
+		perSecond float64
+		expected  string
+	}{
+		{"growth over an hour", 10 * MB, 10*MB + 3*MB/2, time.Hour, 1.5 * float64(MB) / 3600, "+1.5 MB/h"},
+		{"growth over a minute", 0, 10 * KB, time.Minute, 10 * 1024 / 60.0, "+600.0 KB/h"},
+		{"shrinkage", 2 * GB, 1 * GB, 2 * time.Hour, -float64(GB) / 7200, "-512.0 MB/h"},
+		{"no change", GB, GB, time.Hour, 0, "+0.0 B/h"},
+		{"zero duration", 0, GB, 0, 0, "+0.0 B/h"},
+		{"saturated growth", 0, math.MaxUint64, time.Nanosecond, float64(math.MaxUint64) / time.Nanosecond.Seconds(), "+16.0 EB/h"},
+		{"saturated shrinkage", math.MaxUint64, 0, time.Nanosecond, -float64(math.MaxUint64) / time.Nanosecond.Seconds(), "-16.0 EB/h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := GrowthRate(tt.old, tt.new, tt.over)
+			if ps := g.PerSecond(); ps != tt.perSecond {
+				t.Errorf("PerSecond() = %v, expected %v", ps, tt.perSecond)
+			}
+			if s := g.String(); s != tt.expected {
+				t.Errorf("String() = %q, expected %q", s, tt.expected)
+			}
+			if g.Rate.Per != tt.over || g.Shrinking != (tt.new < tt.old) {
+				t.Errorf("GrowthRate() = %+v", g)
+			}
+		})
+	}
+}
+
+func TestTolerateRateSuffix(t *testing.T) {
+	originTolerate := TolerateRateSuffix
+	defer func() { TolerateRateSuffix = originTolerate }()
+
+	tests := []struct {
+		input    string
+		expected ByteSize
+	}{
+		{"10 MB/s", 10 * MB},
+		{"10MB/s", 10 * MB},
+		{"10 MB / min", 10 * MB},
+		{"1.5 GB/h", ByteSize(1.5 * float64(GB))},
+		{"512 KB/sec", 512 * KB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			TolerateRateSuffix = false
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("Parse(%q) with TolerateRateSuffix off: expected error", tt.input)
+			}
+
+			TolerateRateSuffix = true
+			b, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if b != tt.expected {
+				t.Errorf("Parse(%q) = %d, expected %d", tt.input, b, tt.expected)
+			}
+		})
+	}
+
+	TolerateRateSuffix = true
+	if _, err := Parse("10 MB/potato"); err == nil {
+		t.Errorf("Parse(%q): expected error for unknown rate suffix", "10 MB/potato")
+	}
+}
+
+func TestAtRate(t *testing.T) {
+	tests := []struct {
+		size     ByteSize
+		rate     float64
+		expected time.Duration
+	}{
+		{MB, 1e6, 1048576 * time.Microsecond},
+		{MB, float64(MB), time.Second},
+		{0, 1e6, 0},
+		{GB, float64(MB), 1024 * time.Second},
+		{EB, 1e-9, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		if d := tt.size.AtRate(tt.rate); d != tt.expected {
+			t.Errorf("(%s).AtRate(%v) = %v, expected %v", tt.size, tt.rate, d, tt.expected)
+		}
+		d, err := tt.size.TransferTime(tt.rate)
+		if err != nil || d != tt.expected {
+			t.Errorf("(%s).TransferTime(%v) = %v, %v; expected %v", tt.size, tt.rate, d, err, tt.expected)
+		}
+	}
+
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		if _, err := MB.TransferTime(rate); err == nil {
+			t.Errorf("TransferTime(%v): expected error", rate)
+		}
+		if d := MB.AtRate(rate); d != 0 {
+			t.Errorf("AtRate(%v) = %v, expected 0", rate, d)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	originalFormat := Format
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	defer func() {
+		Format = originalFormat
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+	}()
+	Format = "%.2f"
+	SetLocale(LocaleEN)
+	LongUnits = false
+
+	tests := []struct {
+		name      string
+		rate      Rate
+		perSecond float64
+		expected  string
+	}{
+		{"bytes per second", NewRate(512, time.Second), 512, "512.00B/s"},
+		{"megabytes per second", NewRate(10*MB, time.Second), float64(10 * MB), "10.00MB/s"},
+		{"kilobytes per minute", NewRate(600*KB, time.Minute), float64(10 * KB), "10.00KB/s"},
+		{"per millisecond", NewRate(KB, time.Millisecond), float64(1000 * KB), "1000.00KB/s"},
+		{"per hour", NewRate(3600*MB, time.Hour), float64(MB), "1.00MB/s"},
+		{"zero window", NewRate(MB, 0), 0, "0.00B/s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.rate.PerSecond(); result != tt.perSecond {
+				t.Errorf("PerSecond() = %v, expected %v", result, tt.perSecond)
+			}
+			if result := tt.rate.String(); result != tt.expected {
+				t.Errorf("String() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Rate
+		fail     bool
+	}{
+		{"10 MB/s", NewRate(10*MB, time.Second), false},
+		{"600KB/min", NewRate(600*KB, time.Minute), false},
+		{" 1.5 GB / h ", NewRate(3*GB/2, time.Hour), false},
+		{"4 KiB/ms", NewRate(4*KB, time.Millisecond), false},
+		{"10 MB", Rate{}, true},
+		{"10 MB/week", Rate{}, true},
+		{"lots/s", Rate{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var r Rate
+			err := r.Parse(tt.input)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("Parse(%q): expected error, received %+v", tt.input, r)
+				}
+				return
+			}
+			if err != nil || r != tt.expected {
+				t.Errorf("Parse(%q) = %+v, %v, expected %+v", tt.input, r, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRateText(t *testing.T) {
+	originalFormat := Format
+	defer func() { Format = originalFormat }()
+	Format = "%.2f "
+
+	for _, r := range []Rate{NewRate(10*MB, time.Second), NewRate(600*KB, time.Minute), NewRate(1234567, time.Hour), NewRate(0, 24*time.Hour)} {
+		text, err := r.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%+v) error = %v", r, err)
+		}
+		var decoded Rate
+		if err := decoded.UnmarshalText(text); err != nil || decoded != r {
+			t.Errorf("UnmarshalText(%q) = %+v, %v, expected %+v", text, decoded, err, r)
+		}
+	}
+	if _, err := NewRate(MB, 2*time.Second).MarshalText(); err == nil {
+		t.Error("MarshalText with a 2s window: expected error")
+	}
+
+	out, err := DecodeHook()(reflect.TypeOf(""), reflect.TypeOf(Rate{}), "10 MB/s")
+	if err != nil || out != NewRate(10*MB, time.Second) {
+		t.Errorf("DecodeHook into Rate = %v, %v", out, err)
+	}
+}
@@ -0,0 +1,86 @@
+package bytesize
+
+import (
+	"math"
+	"testing"
+)
+
+// roundTripSizes are the values every formatter is checked against.
+var roundTripSizes = []ByteSize{
+	0, 1, 2, 5, 21, 512, 1000, 1023, KB, 1536, 999999, MB, 3 * MB / 2,
+	1610612736, 7 * GB, 1234567890123, TB, 5 * PB, EB, 15 * EB, math.MaxUint64,
+}
+
+// assertRoundTrip checks that out, produced by formatting want, parses back
+// to want within the formatter's precision. tolerance is relative to want.
+func assertRoundTrip(t *testing.T, formatter string, out string, want ByteSize, tolerance float64) {
+	t.Helper()
+
+	got, err := Parse(out)
+	if err != nil {
+		t.Errorf("%s: Parse(%q) of %d failed: %v", formatter, out, uint64(want), err)
+		return
+	}
+	if diff := math.Abs(float64(got) - float64(want)); diff > float64(want)*tolerance+0.5 {
+		t.Errorf("%s: Parse(%q) = %d, expected %d", formatter, out, uint64(got), uint64(want))
+	}
+}
+
+// TestFormattersRoundTrip checks that the output of every single-value
+// formatter can be fed back to Parse. Labeled and DualString are excluded:
+// they annotate a size or show two of them rather than render one value.
+func TestFormattersRoundTrip(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	originalUseIECUnits := UseIECUnits
+	originalSystem := CurrentSystem
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+		UseIECUnits = originalUseIECUnits
+		CurrentSystem = originalSystem
+	}()
+
+	// Two decimals of a unit or mantissa are accurate to within 0.5%.
+	const twoDecimals = 0.005
+
+	for _, system := range []UnitSystem{SystemBinary, SystemDecimal} {
+		SetUnitSystem(system)
+		for _, locale := range []Locale{LocaleEN, LocaleRU, LocaleHI, LocaleDE, LocaleFR, LocalePL} {
+			SetLocale(locale)
+			for _, format := range []string{"%.2f ", "%.2f", "%.0f "} {
+				Format = format
+				tolerance := twoDecimals
+				if format == "%.0f " {
+					tolerance = 0.5
+				}
+				for _, longUnits := range []bool{false, true} {
+					LongUnits = longUnits
+					for _, size := range roundTripSizes {
+						assertRoundTrip(t, "String", size.String(), size, tolerance)
+
+						_, nice := size.NiceString()
+						assertRoundTrip(t, "NiceString", nice, size.NiceRound(), tolerance)
+					}
+				}
+
+				LongUnits = false
+				UseIECUnits = true
+				for _, size := range roundTripSizes {
+					assertRoundTrip(t, "String (IEC)", size.String(), size, tolerance)
+				}
+				UseIECUnits = false
+			}
+
+			Format = "%.2f "
+			for _, size := range roundTripSizes {
+				assertRoundTrip(t, "GroupedBytes", size.GroupedBytes(), size, 0)
+				assertRoundTrip(t, "GroupedString", size.GroupedString(), size, twoDecimals)
+				assertRoundTrip(t, "Engineering", size.Engineering(), size, twoDecimals)
+				assertRoundTrip(t, "Format", size.Format("%.3f ", "MB", true), size, 0.0005*float64(MB)/float64(size))
+			}
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package bytesize
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -235,7 +238,7 @@ func TestEnglishFormatting(t *testing.T) {
 	}()
 
 	SetLocale(LocaleEN)
-	Format = "%.2f"
+	Format = "%.2f "
 	LongUnits = true
 
 	tests := []struct {
@@ -243,20 +246,40 @@ func TestEnglishFormatting(t *testing.T) {
 		size     ByteSize
 		expected string
 	}{
+		{"English zero bytes", New(0), "0.00 bytes"},
 		{"English byte singular", New(1), "1.00 byte"},
-		{"English bytes plural", New(2), "2.00 bytes"}, // Note: исходная библиотека может не поддерживать это
+		{"English bytes plural", New(2), "2.00 bytes"},
+		{"English fractional kilobytes", 3 * KB / 2, "1.50 kilobytes"},
 		{"English kilobyte", KB, "1.00 kilobyte"},
 		{"English megabyte", MB, "1.00 megabyte"},
 		{"English gigabyte", GB, "1.00 gigabyte"},
+		{"English rounded to one kilobyte", KB + 1, "1.00 kilobyte"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.size.String()
-			// Для английского может потребоваться корректировка логики множественного числа
-			t.Logf("Size %d String() = %q (expected %q)", tt.size, result, tt.expected)
+			if result := tt.size.String(); result != tt.expected {
+				t.Errorf("Size %d String() = %q, expected %q", tt.size, result, tt.expected)
+			}
 		})
 	}
+
+	// Форма множественного числа выбирается по выведенному числу
+	Format = "%.0f "
+	for _, tt := range []struct {
+		size     ByteSize
+		expected string
+	}{
+		{New(0), "0 bytes"},
+		{KB + 1, "1 kilobyte"},
+		{2*KB - 1, "2 kilobytes"},
+		{3 * KB / 2, "2 kilobytes"},
+		{MB + 100*KB, "1 megabyte"},
+	} {
+		if result := tt.size.String(); result != tt.expected {
+			t.Errorf("Size %d String() = %q, expected %q", tt.size, result, tt.expected)
+		}
+	}
 }
 
 func TestParseErrors(t *testing.T) {
@@ -398,3 +421,455 @@ func TestBackwardCompatibility(t *testing.T) {
 		t.Errorf("Backward compatibility: Parse = %d, expected %d", parsed, expectedSize)
 	}
 }
+
+func TestAutoLocale(t *testing.T) {
+	// Сохраняем оригинальную локаль
+	originalLocale := CurrentLocale
+	defer func() {
+		CurrentLocale = originalLocale
+	}()
+
+	tests := []struct {
+		name     string
+		lcAll    string
+		lang     string
+		expected Locale
+	}{
+		{"LANG русский", "", "ru_RU.UTF-8", LocaleRU},
+		{"LANG английский", "", "en_US.UTF-8", LocaleEN},
+		{"LANG без региона", "", "ru", LocaleRU},
+		{"LC_ALL важнее LANG", "ru_RU.UTF-8", "en_US.UTF-8", LocaleRU},
+		{"Неизвестный язык", "", "xx_XX.UTF-8", LocaleEN},
+		{"Локаль C", "", "C", LocaleEN},
+		{"Пустое окружение", "", "", LocaleEN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_MESSAGES", "")
+			t.Setenv("LANG", tt.lang)
+
+			if result := AutoLocale(); result != tt.expected {
+				t.Errorf("AutoLocale() = %q, expected %q", result, tt.expected)
+			}
+
+			SetAutoLocale()
+			if CurrentLocale != tt.expected {
+				t.Errorf("SetAutoLocale(): CurrentLocale = %q, expected %q", CurrentLocale, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRussianCaseInsensitive(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ByteSize
+	}{
+		{"Строчные", "2 кб", 2 * KB},
+		{"Первая заглавная", "2 Кб", 2 * KB},
+		{"Вторая заглавная", "2 кБ", 2 * KB},
+		{"Заглавные", "2 КБ", 2 * KB},
+		{"Строчные мегабайты", "3 мб", 3 * MB},
+		{"Смешанные эксабайты", "1 эБ", EB},
+		{"Смешанное длинное", "5 КилоБайтов", 5 * KB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseWithLocale(tt.input, LocaleRU)
+			if err != nil {
+				t.Errorf("ParseWithLocale(%q) error = %v", tt.input, err)
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("ParseWithLocale(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeDefinitions(t *testing.T) {
+	base := localizedUnits[LocaleEN]
+	overlay := unitDefinitions{
+		longUnits:  map[ByteSize]string{KB: "кило"},
+		shortUnits: map[ByteSize]string{KB: "К"},
+		parseMap:   map[string]ByteSize{"КИЛО": KB, "KB": MB},
+	}
+
+	merged := mergeDefinitions(base, overlay)
+
+	if merged.longUnits[KB] != "кило" || merged.shortUnits[KB] != "К" {
+		t.Errorf("overlay units not applied: long %q, short %q", merged.longUnits[KB], merged.shortUnits[KB])
+	}
+	if merged.longUnits[MB] != "megabyte" || merged.shortUnits[MB] != "MB" {
+		t.Errorf("base units lost: long %q, short %q", merged.longUnits[MB], merged.shortUnits[MB])
+	}
+	if merged.parseMap["KB"] != MB {
+		t.Errorf("parseMap[KB] = %d, expected overlay value %d", merged.parseMap["KB"], MB)
+	}
+	if merged.parseMap["КИЛО"] != KB || merged.parseMap["GIGABYTE"] != GB {
+		t.Errorf("merged parseMap is missing keys")
+	}
+	if merged.iecUnits[KB] != "KiB" {
+		t.Errorf("iecUnits[KB] = %q, expected base value", merged.iecUnits[KB])
+	}
+	if merged.pluralFunc == nil || merged.pluralFunc(2, MB, merged) != "megabytes" {
+		t.Errorf("pluralFunc not kept from base")
+	}
+
+	// Исходные определения не должны меняться
+	if base.parseMap["KB"] != KB || base.longUnits[KB] != "kilobyte" {
+		t.Errorf("mergeDefinitions modified base")
+	}
+}
+
+func TestParts(t *testing.T) {
+	// Сохраняем оригинальную локаль
+	originalLocale := CurrentLocale
+	defer func() {
+		CurrentLocale = originalLocale
+	}()
+
+	tests := []struct {
+		name     string
+		locale   Locale
+		size     ByteSize
+		expected SizeParts
+	}{
+		{"English singular", LocaleEN, MB, SizeParts{1, "MB", "megabyte", MB}},
+		{"English plural", LocaleEN, 3 * MB / 2, SizeParts{1.5, "MB", "megabytes", MB}},
+		{"English bytes", LocaleEN, 512, SizeParts{512, "B", "bytes", B}},
+		{"Русский 1", LocaleRU, GB, SizeParts{1, "ГБ", "гигабайт", GB}},
+		{"Русский 2", LocaleRU, 2 * GB, SizeParts{2, "ГБ", "гигабайта", GB}},
+		{"Русский 5", LocaleRU, 5 * KB, SizeParts{5, "КБ", "килобайтов", KB}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLocale(tt.locale)
+			if parts := tt.size.Parts(); parts != tt.expected {
+				t.Errorf("Size %d Parts() = %+v, expected %+v", tt.size, parts, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValueUnit(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+	}()
+
+	tests := []struct {
+		name      string
+		locale    Locale
+		longUnits bool
+		size      ByteSize
+		value     float64
+		unit      string
+	}{
+		{"English", LocaleEN, false, 2 * MB, 2, "MB"},
+		{"English long", LocaleEN, true, 2 * MB, 2, "megabytes"},
+		{"English fraction", LocaleEN, false, 3 * GB / 2, 1.5, "GB"},
+		{"Русский", LocaleRU, false, 2 * MB, 2, "МБ"},
+		{"Русский длинный", LocaleRU, true, 2 * MB, 2, "мегабайта"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLocale(tt.locale)
+			LongUnits = tt.longUnits
+			if value, unit := tt.size.ValueUnit(); value != tt.value || unit != tt.unit {
+				t.Errorf("Size %d ValueUnit() = (%v, %q), expected (%v, %q)", tt.size, value, unit, tt.value, tt.unit)
+			}
+		})
+	}
+}
+
+func TestGroupedBytes(t *testing.T) {
+	// Сохраняем оригинальную локаль
+	originalLocale := CurrentLocale
+	defer func() {
+		CurrentLocale = originalLocale
+	}()
+
+	tests := []struct {
+		name     string
+		locale   Locale
+		size     ByteSize
+		expected string
+	}{
+		{"English small", LocaleEN, 999, "999 B"},
+		{"English thousands", LocaleEN, 1000, "1,000 B"},
+		{"English millions", LocaleEN, 1234567, "1,234,567 B"},
+		{"English max", LocaleEN, 18446744073709551615, "18,446,744,073,709,551,615 B"},
+		{"Русские тысячи", LocaleRU, 1024, "1\u2009024 Б"},
+		{"Русские миллионы", LocaleRU, MB, "1\u2009048\u2009576 Б"},
+		{"Русские без групп", LocaleRU, 512, "512 Б"},
+	}
+
+	// Русская типографика использует тонкую шпацию (U+2009)
+	if sep := localizedUnits[LocaleRU].groupSeparator; sep != "\u2009" {
+		t.Errorf("RU groupSeparator = %q, expected U+2009", sep)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLocale(tt.locale)
+			if result := tt.size.GroupedBytes(); result != tt.expected {
+				t.Errorf("Size %d GroupedBytes() = %q, expected %q", tt.size, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRussianParseErrors(t *testing.T) {
+	// Сохраняем оригинальную локаль
+	originalLocale := CurrentLocale
+	defer func() {
+		CurrentLocale = originalLocale
+	}()
+
+	tests := []struct {
+		input    string
+		locale   Locale
+		expected string
+	}{
+		{"1024 XB", LocaleRU, "нераспознанная единица измерения: XB"},
+		{"1024", LocaleRU, "нераспознанная единица измерения"},
+		{"МБ", LocaleRU, `некорректное число: ""`},
+		{"1024 XB", LocaleEN, "unrecognized size suffix: XB"},
+		{"МБ", LocaleEN, "unrecognized size suffix: МБ"},
+		{"-1 МБ", LocaleRU, `отрицательный размер недопустим: "-1"`},
+		{"-1 MB", LocaleEN, `negative size not allowed: "-1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.locale)+" "+tt.input, func(t *testing.T) {
+			SetLocale(tt.locale)
+			_, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) expected error, got nil", tt.input)
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Parse(%q) error = %q, expected %q", tt.input, err, tt.expected)
+			}
+		})
+	}
+
+	// Неподдерживаемая локаль сообщает об ошибке по-английски
+	if _, err := ParseWithLocale("1 MB", Locale("xx")); err == nil || err.Error() != "unsupported locale: xx" {
+		t.Errorf("ParseWithLocale(xx) error = %v", err)
+	}
+}
+
+func TestParseSentinelErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		locale   Locale
+		sentinel error
+	}{
+		{"1024 XB", LocaleEN, ErrUnknownUnit},
+		{"1024 XB", LocaleRU, ErrUnknownUnit},
+		{"", LocaleEN, ErrEmptyInput},
+		{"   ", LocaleRU, ErrEmptyInput},
+		{"1024", LocaleEN, ErrNoUnit},
+		{"1.2.3 MB", LocaleEN, ErrInvalidNumber},
+		{"МБ", LocaleRU, ErrInvalidNumber},
+		{"-1 MB", LocaleEN, ErrNegativeSize},
+		{"1 MB", Locale("xx"), ErrUnsupportedLocale},
+		{"1 \xff", LocaleEN, ErrInvalidEncoding},
+		{"20 EB", LocaleEN, ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.locale)+" "+tt.input, func(t *testing.T) {
+			_, err := ParseWithLocale(tt.input, tt.locale)
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("ParseWithLocale(%q) error = %v, expected %v", tt.input, err, tt.sentinel)
+			}
+		})
+	}
+
+	// Сообщение остаётся локализованным
+	_, err := ParseWithLocale("1024 XB", LocaleRU)
+	if err == nil || err.Error() != "нераспознанная единица измерения: XB" {
+		t.Errorf("ParseWithLocale error = %v", err)
+	}
+
+	p, _ := NewParser(LocaleEN)
+	if _, err := p.Parse("1024 XB"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("Parser.Parse error = %v, expected %v", err, ErrUnknownUnit)
+	}
+	if _, err := NewParser(Locale("xx")); !errors.Is(err, ErrUnsupportedLocale) {
+		t.Errorf("NewParser error = %v, expected %v", err, ErrUnsupportedLocale)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	originalLocale := CurrentLocale
+	defer func() { CurrentLocale = originalLocale }()
+	SetLocale(LocaleEN)
+
+	b, err := ParseLocale("2 КБ", LocaleRU)
+	if err != nil || b != 2*KB {
+		t.Fatalf("ParseLocale(\"2 КБ\", ru) = %d, %v, expected %d", b, err, 2*KB)
+	}
+	// Глобальная локаль не меняется
+	if CurrentLocale != LocaleEN {
+		t.Fatalf("ParseLocale changed CurrentLocale to %s", CurrentLocale)
+	}
+	if _, err := Parse("2 КБ"); err == nil {
+		t.Fatal("Parse(\"2 КБ\") in English: expected error")
+	}
+
+	if _, err := ParseLocale("2 KB", Locale("xx")); !errors.Is(err, ErrUnsupportedLocale) {
+		t.Fatalf("ParseLocale(xx) error = %v, expected %v", err, ErrUnsupportedLocale)
+	}
+}
+
+func TestBreakdownWords(t *testing.T) {
+	size := GB + 200*MB + 5
+	tests := []struct {
+		name     string
+		size     ByteSize
+		locale   Locale
+		maxParts int
+		expected string
+	}{
+		{"English two parts", size, LocaleEN, 2, "1 gigabyte and 200 megabytes"},
+		{"English all parts", size, LocaleEN, 0, "1 gigabyte, 200 megabytes and 5 bytes"},
+		{"English one part", size, LocaleEN, 1, "1 gigabyte"},
+		{"English single unit", 2 * KB, LocaleEN, 3, "2 kilobytes"},
+		{"Русский две части", size, LocaleRU, 2, "1 гигабайт и 200 мегабайтов"},
+		{"Русский все части", size, LocaleRU, 0, "1 гигабайт, 200 мегабайтов и 5 байтов"},
+		{"Русский склонения", 3*GB + 21*MB + 2*KB, LocaleRU, 0, "3 гигабайта, 21 мегабайт и 2 килобайта"},
+		{"Неизвестная локаль", 2 * KB, Locale("xx"), 0, "2 kilobytes"},
+		{"English zero parts dropped", GB + 5, LocaleEN, 0, "1 gigabyte and 5 bytes"},
+		{"English rounded down", GB + 200*MB + 400*KB, LocaleEN, 2, "1 gigabyte and 200 megabytes"},
+		{"English rounded up", GB + 200*MB + 600*KB, LocaleEN, 2, "1 gigabyte and 201 megabytes"},
+		{"English carry", GB + 1023*MB + 700*KB, LocaleEN, 2, "2 gigabytes"},
+		{"English carry into one part", 3*GB/2 + 5, LocaleEN, 1, "2 gigabytes"},
+		{"English maximum", ByteSize(math.MaxUint64), LocaleEN, 1, "15 exabytes"},
+		{"Русский округление", GB + 200*MB + 600*KB, LocaleRU, 2, "1 гигабайт и 201 мегабайт"},
+		{"Русский перенос", 2*GB - 300*KB, LocaleRU, 2, "2 гигабайта"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.size.BreakdownWords(tt.locale, tt.maxParts); result != tt.expected {
+				t.Errorf("BreakdownWords(%s, %d) = %q, expected %q", tt.locale, tt.maxParts, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPluralFunc(t *testing.T) {
+	// Новая локаль задаёт свои правила без изменения кода форматирования
+	const localeXX Locale = "xx"
+	localizedUnits[localeXX] = mergeDefinitions(localizedUnits[LocaleEN], unitDefinitions{
+		pluralFunc: func(value float64, unit ByteSize, forms unitDefinitions) string {
+			return strings.ToUpper(forms.longUnits[unit])
+		},
+	})
+	defer delete(localizedUnits, localeXX)
+
+	if result := (2 * MB).FormatOpts(FormatOptions{Locale: localeXX, LongUnits: true}); result != "2.00 MEGABYTE" {
+		t.Errorf("FormatOpts() = %q, expected the locale's pluralFunc to apply", result)
+	}
+
+	// Существующие правила не изменились
+	tests := []struct {
+		locale   Locale
+		size     ByteSize
+		expected string
+	}{
+		{LocaleEN, MB, "1 megabyte"},
+		{LocaleEN, 2 * MB, "2 megabytes"},
+		{LocaleRU, 2 * MB, "2 мегабайта"},
+		{LocaleRU, 5 * MB, "5 мегабайтов"},
+		{LocaleHI, 5 * MB, "5 मेगाबाइट"},
+	}
+	for _, tt := range tests {
+		opts := FormatOptions{Locale: tt.locale, LongUnits: true, Format: "%.0f "}
+		if result := tt.size.FormatOpts(opts); result != tt.expected {
+			t.Errorf("%s: FormatOpts() = %q, expected %q", tt.locale, result, tt.expected)
+		}
+	}
+}
+
+func TestRussianGroupedString(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalFormat := Format
+	originalLongUnits := LongUnits
+	defer func() {
+		CurrentLocale = originalLocale
+		Format = originalFormat
+		LongUnits = originalLongUnits
+		SetUnitLadder(nil)
+	}()
+
+	SetLocale(LocaleRU)
+	Format = "%.2f "
+
+	// Тысячи отделяются узким пробелом, дробная часть — запятой
+	if result := (1010 * MB).GroupedString(); result != "1\u2009010,00 МБ" {
+		t.Errorf("GroupedString() = %q", result)
+	}
+
+	SetUnitLadder([]ByteSize{B, MB})
+	if result := (1234*MB + 512*KB).GroupedString(); result != "1\u2009234,50 МБ" {
+		t.Errorf("GroupedString() = %q", result)
+	}
+
+	LongUnits = true
+	if result := (1234*MB + 512*KB).GroupedString(); result != "1\u2009234,50 мегабайта" {
+		t.Errorf("GroupedString() = %q", result)
+	}
+}
+
+func TestRussianCommaDecimal(t *testing.T) {
+	tests := []struct {
+		locale   Locale
+		input    string
+		expected ByteSize
+	}{
+		// Запятая — десятичный разделитель в русской локали
+		{LocaleRU, "1,5 МБ", 3 * MB / 2},
+		{LocaleRU, "1,5MB", 3 * MB / 2},
+		{LocaleRU, "0,25 ГБ", GB / 4},
+		{LocaleRU, "1,000 КБ", KB},
+		{LocaleRU, "1\u2009010,50 КБ", 1010*KB + 512},
+		// Точка по-прежнему работает
+		{LocaleRU, "1.5 MB", 3 * MB / 2},
+		{LocaleEN, "1.5 MB", 3 * MB / 2},
+		// В английской локали запятая разделяет тысячи
+		{LocaleEN, "1,000 B", 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.locale)+" "+tt.input, func(t *testing.T) {
+			result, err := ParseWithLocale(tt.input, tt.locale)
+			if err != nil {
+				t.Fatalf("ParseWithLocale(%q, %s) error = %v", tt.input, tt.locale, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseWithLocale(%q, %s) = %d, expected %d", tt.input, tt.locale, result, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"1,5,5 МБ", "1.5,5 МБ", "1,5.5 МБ"} {
+		if _, err := ParseWithLocale(input, LocaleRU); err == nil {
+			t.Errorf("ParseWithLocale(%q, ru): expected error", input)
+		}
+	}
+	if _, err := ParseWithLocale("1,5 MB", LocaleEN); err == nil {
+		t.Error("ParseWithLocale(\"1,5 MB\", en): expected error")
+	}
+}
@@ -0,0 +1,94 @@
+package bytesize
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SignedByteSize is a byte size that may be negative, such as the change in
+// disk usage between two measurements. It is formatted and parsed like
+// ByteSize, with a leading "-" for negative values.
+type SignedByteSize int64
+
+// Diff returns b minus other as a signed size, so a shrink is negative
+// instead of wrapping around. Differences beyond the range of
+// SignedByteSize saturate.
+func (b ByteSize) Diff(other ByteSize) SignedByteSize {
+	if b >= other {
+		if d := b - other; d <= math.MaxInt64 {
+			return SignedByteSize(d)
+		}
+		return math.MaxInt64
+	}
+	if d := other - b; d <= 1<<63 {
+		return SignedByteSize(-int64(d-1)) - 1
+	}
+	return math.MinInt64
+}
+
+// Abs returns the magnitude of s as a ByteSize. Unlike -s it is correct for
+// the most negative SignedByteSize.
+func (s SignedByteSize) Abs() ByteSize {
+	if s < 0 {
+		return ByteSize(-(s + 1)) + 1
+	}
+	return ByteSize(s)
+}
+
+// String returns the string form of s: the string form of its magnitude,
+// with the same unit, locale and format settings as ByteSize.String, and a
+// leading "-" if s is negative, e.g. "-2.00 KB".
+func (s SignedByteSize) String() string {
+	if s < 0 {
+		return "-" + s.Abs().String()
+	}
+	return s.Abs().String()
+}
+
+// ParseSigned parses a byte size string that may start with "-" or "+",
+// such as "-1.5 GB", like Parse does for unsigned sizes. It returns
+// ErrOverflow if the size does not fit in a SignedByteSize.
+func ParseSigned(s string) (SignedByteSize, error) {
+	s = strings.TrimSpace(s)
+	magnitude, negative := strings.CutPrefix(s, "-")
+	if rest := strings.TrimSpace(magnitude); negative && (strings.HasPrefix(rest, "+") || strings.HasPrefix(rest, "-")) {
+		// Parse would strip the second sign, accepting "-+1 MB".
+		return 0, parseError(getLocale(), errInvalidNumber, strconv.Quote(s))
+	}
+	b, err := Parse(magnitude)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case negative && b == 0:
+		return 0, nil
+	case negative && b <= 1<<63:
+		return -SignedByteSize(b-1) - 1, nil
+	case !negative && b <= math.MaxInt64:
+		return SignedByteSize(b), nil
+	}
+	return 0, ErrOverflow
+}
+
+// UnmarshalText parses text with ParseSigned and sets the value of s.
+// It implements the encoding.TextUnmarshaler interface.
+func (s *SignedByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseSigned(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// MarshalText returns the form of s that UnmarshalText restores exactly:
+// the MarshalText form of its magnitude with a leading "-" if s is
+// negative. It implements the encoding.TextMarshaler interface.
+func (s SignedByteSize) MarshalText() ([]byte, error) {
+	text, err := s.Abs().MarshalText()
+	if err != nil || s >= 0 {
+		return text, err
+	}
+	return append([]byte("-"), text...), nil
+}
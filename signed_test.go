@@ -0,0 +1,121 @@
+package bytesize
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     ByteSize
+		expected SignedByteSize
+	}{
+		{"growth", 3 * MB, MB, SignedByteSize(2 * MB)},
+		{"no change", GB, GB, 0},
+		{"shrink", KB, 3 * KB, -SignedByteSize(2 * KB)},
+		{"largest growth", math.MaxInt64, 0, math.MaxInt64},
+		{"saturated growth", math.MaxUint64, 0, math.MaxInt64},
+		{"largest shrink", 0, 1 << 63, math.MinInt64},
+		{"saturated shrink", 0, math.MaxUint64, math.MinInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.a.Diff(tt.b); result != tt.expected {
+				t.Errorf("%d.Diff(%d) = %d, expected %d", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSignedString(t *testing.T) {
+	originalLocale := CurrentLocale
+	originalLongUnits := LongUnits
+	originalFormat := Format
+	defer func() {
+		CurrentLocale = originalLocale
+		LongUnits = originalLongUnits
+		Format = originalFormat
+	}()
+
+	SetLocale(LocaleEN)
+	LongUnits = false
+	Format = "%.2f"
+
+	tests := []struct {
+		size     SignedByteSize
+		expected string
+	}{
+		{-2 * SignedByteSize(KB), "-2.00KB"},
+		{0, "0.00B"},
+		{SignedByteSize(3 * MB / 2), "1.50MB"},
+		{math.MinInt64, "-8.00EB"},
+	}
+	for _, tt := range tests {
+		if result := tt.size.String(); result != tt.expected {
+			t.Errorf("SignedByteSize(%d).String() = %q, expected %q", int64(tt.size), result, tt.expected)
+		}
+	}
+
+	SetLocale(LocaleRU)
+	LongUnits = true
+	Format = "%.0f "
+	if result := (-5 * SignedByteSize(MB)).String(); result != "-5 мегабайтов" {
+		t.Errorf("String() in Russian = %q", result)
+	}
+}
+
+func TestParseSigned(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SignedByteSize
+		err      error
+	}{
+		{"-1.5 KB", -1536, nil},
+		{"+2 MB", SignedByteSize(2 * MB), nil},
+		{"2 MB", SignedByteSize(2 * MB), nil},
+		{"-0 B", 0, nil},
+		{"-8 EB", math.MinInt64, nil},
+		{"8 EB", 0, ErrOverflow},
+		{"-9 EB", 0, ErrOverflow},
+	}
+	for _, tt := range tests {
+		result, err := ParseSigned(tt.input)
+		if !errors.Is(err, tt.err) || result != tt.expected {
+			t.Errorf("ParseSigned(%q) = %d, %v, expected %d, %v", tt.input, result, err, tt.expected, tt.err)
+		}
+	}
+	for _, input := range []string{"--1 MB", "-+1 MB", "- +1 MB", "+-1 MB", "++1 MB", "-lots", ""} {
+		if _, err := ParseSigned(input); err == nil {
+			t.Errorf("ParseSigned(%q): expected error", input)
+		}
+	}
+	if _, err := ParseSigned("-+1 MB"); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("ParseSigned(%q) error = %v, expected ErrInvalidNumber", "-+1 MB", err)
+	}
+}
+
+func TestSignedText(t *testing.T) {
+	originalFormat := Format
+	defer func() { Format = originalFormat }()
+	Format = "%.2f "
+
+	for _, size := range []SignedByteSize{0, 1, -1, -1536, SignedByteSize(7 * GB), -1234567, math.MaxInt64, math.MinInt64} {
+		text, err := size.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d) error = %v", int64(size), err)
+		}
+		var decoded SignedByteSize
+		if err := decoded.UnmarshalText(text); err != nil || decoded != size {
+			t.Errorf("UnmarshalText(%q) = %d, %v, expected %d", text, int64(decoded), err, int64(size))
+		}
+	}
+
+	out, err := DecodeHook()(reflect.TypeOf(""), reflect.TypeOf(SignedByteSize(0)), "-2 KB")
+	if err != nil || out != -2*SignedByteSize(KB) {
+		t.Errorf("DecodeHook into SignedByteSize = %v, %v", out, err)
+	}
+}
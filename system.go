@@ -0,0 +1,75 @@
+package bytesize
+
+import "strings"
+
+// UnitSystem selects how many bytes the unit prefixes stand for. The unit
+// constants KB, MB, … always keep their binary values; the system only
+// changes how sizes are parsed and displayed.
+type UnitSystem int
+
+const (
+	// SystemBinary makes every prefix 1024 times the previous one, so
+	// "1 KB" is 1024 bytes. It is the default.
+	SystemBinary UnitSystem = iota
+	// SystemDecimal makes every prefix 1000 times the previous one, as SI
+	// and disk vendors do, so "1 kB" is 1000 bytes.
+	SystemDecimal
+)
+
+// CurrentSystem is the unit system used for parsing and formatting.
+var CurrentSystem = SystemBinary
+
+// getSystem returns CurrentSystem.
+func getSystem() UnitSystem {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return CurrentSystem
+}
+
+// SetUnitSystem sets the current unit system. If the system is not known,
+// the current system remains unchanged. It is safe to call while other
+// goroutines format or parse sizes.
+func SetUnitSystem(system UnitSystem) {
+	if system == SystemBinary || system == SystemDecimal {
+		configMu.Lock()
+		CurrentSystem = system
+		configMu.Unlock()
+	}
+}
+
+// String returns "binary" or "decimal".
+func (s UnitSystem) String() string {
+	if s == SystemDecimal {
+		return "decimal"
+	}
+	return "binary"
+}
+
+// decimalSizes maps each unit constant to its size in the decimal system.
+var decimalSizes = map[ByteSize]ByteSize{
+	B:  1,
+	KB: 1e3,
+	MB: 1e6,
+	GB: 1e9,
+	TB: 1e12,
+	PB: 1e15,
+	EB: 1e18,
+}
+
+// unitBytes returns the number of bytes unit stands for in s.
+func (s UnitSystem) unitBytes(unit ByteSize) ByteSize {
+	if size, ok := decimalSizes[unit]; ok && s == SystemDecimal {
+		return size
+	}
+	return unit
+}
+
+// parsedUnitBytes returns the number of bytes unit stands for in s when it
+// was written as suffix. IEC spellings such as "KiB" are binary in either
+// system.
+func (s UnitSystem) parsedUnitBytes(unit ByteSize, suffix string, units unitDefinitions) ByteSize {
+	if unit != B && strings.EqualFold(suffix, units.iecUnits[unit]) {
+		return unit
+	}
+	return s.unitBytes(unit)
+}
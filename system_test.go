@@ -0,0 +1,92 @@
+package bytesize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnitSystems(t *testing.T) {
+	originalSystem := CurrentSystem
+	originalFormat := Format
+	defer func() {
+		CurrentSystem = originalSystem
+		Format = originalFormat
+	}()
+	Format = "%.2f"
+
+	tests := []struct {
+		suffix     string
+		unit       ByteSize
+		decimal    ByteSize
+		binaryOut  string
+		decimalOut string
+	}{
+		{"kB", KB, 1e3, "1.00KB", "1.00kB"},
+		{"MB", MB, 1e6, "1.00MB", "1.00MB"},
+		{"GB", GB, 1e9, "1.00GB", "1.00GB"},
+		{"TB", TB, 1e12, "1.00TB", "1.00TB"},
+		{"PB", PB, 1e15, "1.00PB", "1.00PB"},
+		{"EB", EB, 1e18, "1.00EB", "1.00EB"},
+	}
+
+	for _, tt := range tests {
+		SetUnitSystem(SystemBinary)
+		if b, err := Parse("1 " + tt.suffix); err != nil || b != tt.unit {
+			t.Errorf("binary: Parse(1 %s) = %d, %v, expected %d", tt.suffix, b, err, tt.unit)
+		}
+		if s := tt.unit.String(); s != tt.binaryOut {
+			t.Errorf("binary: (%d).String() = %q, expected %q", tt.unit, s, tt.binaryOut)
+		}
+
+		SetUnitSystem(SystemDecimal)
+		if b, err := Parse("1 " + tt.suffix); err != nil || b != tt.decimal {
+			t.Errorf("decimal: Parse(1 %s) = %d, %v, expected %d", tt.suffix, b, err, tt.decimal)
+		}
+		if s := tt.decimal.String(); s != tt.decimalOut {
+			t.Errorf("decimal: (%d).String() = %q, expected %q", tt.decimal, s, tt.decimalOut)
+		}
+	}
+
+	// The constants themselves never change.
+	if KB != 1024 || MB != 1024*1024 {
+		t.Error("unit constants changed with the unit system")
+	}
+
+	// IEC spellings are binary in either system.
+	if b, err := Parse("1 KiB"); err != nil || b != KB {
+		t.Errorf("decimal: Parse(1 KiB) = %d, %v, expected %d", b, err, KB)
+	}
+	if s := ByteSize(1536).Format("%.2f ", "KiB", false); s != "1.50 KiB" {
+		t.Errorf("decimal: Format(KiB) = %q, expected %q", s, "1.50 KiB")
+	}
+	if s := (3 * MB).Format("%.0f ", "mib", false); s != "3 MiB" {
+		t.Errorf("decimal: Format(mib) = %q, expected %q", s, "3 MiB")
+	}
+	if s := ByteSize(1500).Format("%.2f ", "kB", false); s != "1.50 kB" {
+		t.Errorf("decimal: Format(kB) = %q, expected %q", s, "1.50 kB")
+	}
+	if s := ByteSize(1536).Labeled(); s != "1.54kB (decimal)" {
+		t.Errorf("decimal: Labeled() = %q", s)
+	}
+	if s := ByteSize(math.MaxUint64).String(); s != "18.45EB" {
+		t.Errorf("decimal: String() of the maximum = %q", s)
+	}
+	if n := ByteSize(900e6).ToNextUnit(); n != 100e6 {
+		t.Errorf("decimal: ToNextUnit() = %d, expected %d", n, ByteSize(100e6))
+	}
+
+	SetUnitSystem(UnitSystem(42))
+	if CurrentSystem != SystemDecimal {
+		t.Error("SetUnitSystem accepted an unknown system")
+	}
+}
+
+func TestParserUnitSystem(t *testing.T) {
+	p, _ := NewParser(LocaleEN, WithUnitSystem(SystemDecimal))
+	if b, err := p.Parse("1.5 MB"); err != nil || b != 1500000 {
+		t.Errorf("Parse(1.5 MB) = %d, %v, expected 1500000", b, err)
+	}
+	if b, err := p.Parse("1 MiB"); err != nil || b != MB {
+		t.Errorf("Parse(1 MiB) = %d, %v, expected %d", b, err, MB)
+	}
+}